@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DataSourceReview is sent by the volume-data-source-validator to the
+// validationWebhook of a VolumePopulator, which returns it with the
+// response set.
+type DataSourceReview struct {
+	metav1.TypeMeta `json:",inline"`
+	// Request is the PVC to validate.
+	// +optional
+	Request *DataSourceReviewRequest `json:"request,omitempty" protobuf:"bytes,1,opt,name=request"`
+	// Response is the verdict of the webhook.
+	// +optional
+	Response *DataSourceReviewResponse `json:"response,omitempty" protobuf:"bytes,2,opt,name=response"`
+}
+
+// DataSourceReviewRequest is the PVC a webhook validates.
+type DataSourceReviewRequest struct {
+	// UID identifies the review. It must be copied to the response.
+	UID types.UID `json:"uid" protobuf:"bytes,1,name=uid"`
+	// Populator is the name of the VolumePopulator whose webhook is called.
+	Populator string `json:"populator" protobuf:"bytes,2,name=populator"`
+	// PVC is the PersistentVolumeClaim. Only the namespace and the spec are
+	// set when the PVC template of a workload is validated.
+	PVC runtime.RawExtension `json:"pvc" protobuf:"bytes,3,name=pvc"`
+}
+
+// DataSourceReviewResponse is the verdict of a webhook.
+type DataSourceReviewResponse struct {
+	// UID of the request.
+	UID types.UID `json:"uid" protobuf:"bytes,1,name=uid"`
+	// Allowed is false if the PVC will not be populated.
+	Allowed bool `json:"allowed" protobuf:"varint,2,name=allowed"`
+	// Reason is a CamelCase code, used as event reason when the PVC is
+	// not allowed.
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,3,opt,name=reason"`
+	// Message explains why the PVC is not allowed.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,4,opt,name=message"`
+	// Warnings are reported on the PVC, also when it is allowed.
+	// +optional
+	Warnings []string `json:"warnings,omitempty" protobuf:"bytes,5,rep,name=warnings"`
+}
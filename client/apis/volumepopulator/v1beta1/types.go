@@ -28,7 +28,13 @@ import (
 // VolumePopulators are cluster scoped.
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="SourceKind",type=string,JSONPath=`.sourceKind`
+// +kubebuilder:printcolumn:name="Suspended",type=boolean,JSONPath=`.suspended`
+// +kubebuilder:metadata:annotations="api-approved.kubernetes.io=https://github.com/kubernetes/enhancements/pull/2934"
+// +kubebuilder:metadata:annotations="populator.storage.k8s.io/bundle-version=v1.11.0"
+// +kubebuilder:validation:XValidation:rule="!has(self.excludedKinds) || size(self.excludedKinds) == 0 || self.sourceKind.kind == '*'",message="excludedKinds is only allowed when the kind of sourceKind is *"
+// +kubebuilder:validation:XValidation:rule="!has(self.suspendReason) || size(self.suspendReason) == 0 || (has(self.suspended) && self.suspended)",message="suspendReason is only allowed while suspended"
 type VolumePopulator struct {
 	metav1.TypeMeta `json:",inline"`
 	// Standard object's metadata.
@@ -36,10 +42,243 @@ type VolumePopulator struct {
 	// +optional
 	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
 
-	// Kind of the data source this populator supports
+	// Kind of the data source this populator supports. The kind must be
+	// CamelCase and the group a DNS subdomain, or empty for the core group.
+	// PersistentVolumeClaims and VolumeSnapshots are handled by Kubernetes
+	// and cannot be registered.
+	//
+	// A kind of "*" registers all kinds of a non-empty group, except the
+	// excludedKinds. A VolumePopulator registering the exact kind always
+	// takes precedence over such a wildcard registration.
+	// +kubebuilder:validation:XValidation:rule="self.kind != ''",message="kind must not be empty"
+	// +kubebuilder:validation:XValidation:rule="self.kind == '' || self.kind == '*' || self.kind.matches('^[A-Z][A-Za-z0-9]*$')",message="kind must be CamelCase, starting with an uppercase letter, or *"
+	// +kubebuilder:validation:XValidation:rule="!(self.kind == '*' && self.group == '')",message="kind * requires a group"
+	// +kubebuilder:validation:XValidation:rule="self.group == '' || (size(self.group) <= 253 && self.group.matches('^[a-z0-9]([-a-z0-9]*[a-z0-9])?([.][a-z0-9]([-a-z0-9]*[a-z0-9])?)*$'))",message="group must be empty or a lowercase DNS subdomain"
+	// +kubebuilder:validation:XValidation:rule="!(self.group == '' && self.kind == 'PersistentVolumeClaim')",message="PersistentVolumeClaim is handled by Kubernetes and cannot be registered"
+	// +kubebuilder:validation:XValidation:rule="!(self.group == 'snapshot.storage.k8s.io' && self.kind == 'VolumeSnapshot')",message="VolumeSnapshot is handled by Kubernetes and cannot be registered"
 	SourceKind metav1.GroupKind `json:"sourceKind" protobuf:"bytes,2,name=sourceKind"`
+
+	// Kinds of the group that a wildcard registration does not register.
+	// Only allowed when the kind of sourceKind is "*".
+	// +optional
+	// +listType=set
+	ExcludedKinds []string `json:"excludedKinds,omitempty" protobuf:"bytes,8,rep,name=excludedKinds"`
+
+	// API versions of the source kind that must be served for the populator
+	// to work. When empty, any served version is sufficient. For wildcard
+	// registrations, they are checked for the kind used by a PVC.
+	// +optional
+	// +listType=set
+	RequiredVersions []string `json:"requiredVersions,omitempty" protobuf:"bytes,4,rep,name=requiredVersions"`
+
+	// Lease held by the controller of this populator, for example its
+	// leader election lease. When set, the populator is considered
+	// unavailable while the lease is expired.
+	// +optional
+	ControllerLease *LeaseReference `json:"controllerLease,omitempty" protobuf:"bytes,5,opt,name=controllerLease"`
+
+	// Rules that PVCs using this populator must satisfy, checked by the
+	// volume-data-source-validator. PVCs that break a rule get a warning
+	// event with the message of the rule.
+	// +optional
+	// +listType=atomic
+	ValidationRules []ValidationRule `json:"validationRules,omitempty" protobuf:"bytes,6,rep,name=validationRules"`
+
+	// Webhook called by the volume-data-source-validator with a
+	// DataSourceReview for PVCs using this populator, for checks that need
+	// the knowledge of the populator.
+	// +optional
+	ValidationWebhook *ValidationWebhook `json:"validationWebhook,omitempty" protobuf:"bytes,7,opt,name=validationWebhook"`
+
+	// Suspended makes the source kind unavailable without deleting the
+	// registration, for example during an incident of the populator. PVCs
+	// using the kind get a PopulatorSuspended warning event. The kind is
+	// not matched by other registrations, such as wildcards, meanwhile.
+	// +optional
+	Suspended bool `json:"suspended,omitempty" protobuf:"varint,9,opt,name=suspended"`
+
+	// SuspendReason explains why the populator is suspended. It is
+	// included in the events of PVCs.
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	SuspendReason string `json:"suspendReason,omitempty" protobuf:"bytes,10,opt,name=suspendReason"`
+
+	// Maximum number of Pending PVCs per namespace that use the source
+	// kind, to protect the populator and the storage backend from bursts.
+	// For wildcard registrations, it applies to every kind of the group
+	// separately. PVCs beyond it get a PopulationQuotaExceeded warning
+	// event, and are rejected by the PVC admission webhook. No limit when
+	// unset.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxPendingPVCsPerNamespace *int32 `json:"maxPendingPVCsPerNamespace,omitempty" protobuf:"varint,11,opt,name=maxPendingPVCsPerNamespace"`
+
+	// Status of the registration as observed by the volume-data-source-validator.
+	// +optional
+	Status *VolumePopulatorStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// LeaseReference identifies a coordination.k8s.io Lease.
+type LeaseReference struct {
+	// Namespace of the Lease.
+	Namespace string `json:"namespace" protobuf:"bytes,1,name=namespace"`
+	// Name of the Lease.
+	Name string `json:"name" protobuf:"bytes,2,name=name"`
+}
+
+// ValidationRule is a CEL expression checked for PVCs using a populator.
+type ValidationRule struct {
+	// Rule is a CEL expression that must evaluate to true. The variables
+	// are pvc, the PVC; source, the data source object; and storageClass,
+	// the StorageClass of the PVC. source and storageClass are null if
+	// they do not exist. quantityValue(string) returns the value of a
+	// resource quantity, e.g. quantityValue(pvc.spec.resources.requests.storage).
+	Rule string `json:"rule" protobuf:"bytes,1,name=rule"`
+	// Message reported when the rule evaluates to false.
+	Message string `json:"message" protobuf:"bytes,2,name=message"`
+}
+
+// ValidationWebhook is a webhook that validates PVCs using a populator.
+type ValidationWebhook struct {
+	// ClientConfig says how to connect to the webhook.
+	ClientConfig WebhookClientConfig `json:"clientConfig" protobuf:"bytes,1,name=clientConfig"`
+	// TimeoutSeconds is how long to wait for the webhook. Defaults to 10
+	// seconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=30
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty" protobuf:"varint,2,opt,name=timeoutSeconds"`
+}
+
+// WebhookClientConfig says how to connect to a webhook, like the
+// clientConfig of admission webhooks. Exactly one of url and service must be
+// set.
+type WebhookClientConfig struct {
+	// URL of the webhook, in https://host:port/path form.
+	// +optional
+	URL *string `json:"url,omitempty" protobuf:"bytes,1,opt,name=url"`
+	// Service of the webhook.
+	// +optional
+	Service *ServiceReference `json:"service,omitempty" protobuf:"bytes,2,opt,name=service"`
+	// PEM encoded CA bundle to verify the certificate of the webhook. The
+	// system trust roots are used when empty.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty" protobuf:"bytes,3,opt,name=caBundle"`
+}
+
+// ServiceReference identifies the Service of a webhook.
+type ServiceReference struct {
+	// Namespace of the Service.
+	Namespace string `json:"namespace" protobuf:"bytes,1,name=namespace"`
+	// Name of the Service.
+	Name string `json:"name" protobuf:"bytes,2,name=name"`
+	// Path of the URL the request is sent to.
+	// +optional
+	Path *string `json:"path,omitempty" protobuf:"bytes,3,opt,name=path"`
+	// Port of the Service. Defaults to 443.
+	// +optional
+	Port *int32 `json:"port,omitempty" protobuf:"varint,4,opt,name=port"`
+}
+
+// VolumePopulatorStatus is the observed state of a VolumePopulator.
+type VolumePopulatorStatus struct {
+	// Conditions describe the current state of the registration.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 }
 
+// WildcardKind as the kind of the sourceKind of a VolumePopulator registers
+// all kinds of its group.
+const WildcardKind = "*"
+
+// PopulatorAnnotation set to "true" on the CustomResourceDefinition of a
+// data source kind asks for a VolumePopulator registering the kind to be
+// created, with the name of the CRD. The registration is owned by the CRD
+// and deleted with it.
+const PopulatorAnnotation = GroupName + "/populator"
+
+// Condition types and reasons reported in VolumePopulatorStatus.
+const (
+	// VolumePopulatorConflicting is true when another VolumePopulator
+	// registers the same source kind.
+	VolumePopulatorConflicting = "Conflicting"
+
+	// VolumePopulatorReasonDuplicateSourceKind means the source kind is
+	// registered by more than one VolumePopulator.
+	VolumePopulatorReasonDuplicateSourceKind = "DuplicateSourceKind"
+	// VolumePopulatorReasonUniqueSourceKind means no other VolumePopulator
+	// registers the source kind.
+	VolumePopulatorReasonUniqueSourceKind = "UniqueSourceKind"
+
+	// VolumePopulatorSourceKindServed is true when the API server serves the
+	// source kind in all required versions.
+	VolumePopulatorSourceKindServed = "SourceKindServed"
+
+	// VolumePopulatorReasonKindServed means the source kind is served in all
+	// required versions.
+	VolumePopulatorReasonKindServed = "KindServed"
+	// VolumePopulatorReasonKindNotServed means no CRD or API group serves
+	// the source kind.
+	VolumePopulatorReasonKindNotServed = "KindNotServed"
+	// VolumePopulatorReasonVersionsNotServed means the source kind is served,
+	// but not in all of the required versions.
+	VolumePopulatorReasonVersionsNotServed = "RequiredVersionsNotServed"
+
+	// VolumePopulatorAvailable is true while the controller lease of the
+	// populator is held. It is only set for populators with a
+	// controllerLease.
+	VolumePopulatorAvailable = "Available"
+
+	// VolumePopulatorReasonLeaseHeld means the controller lease is held and
+	// has not expired.
+	VolumePopulatorReasonLeaseHeld = "LeaseHeld"
+	// VolumePopulatorReasonLeaseExpired means the controller lease was not
+	// renewed in time or was released.
+	VolumePopulatorReasonLeaseExpired = "LeaseExpired"
+	// VolumePopulatorReasonLeaseNotFound means the controller lease does
+	// not exist.
+	VolumePopulatorReasonLeaseNotFound = "LeaseNotFound"
+
+	// VolumePopulatorSuspended is true while the populator is suspended.
+	// It is only set for suspended populators.
+	VolumePopulatorSuspended = "Suspended"
+
+	// VolumePopulatorReasonSuspendedByUser means suspended is set in the
+	// spec of the populator.
+	VolumePopulatorReasonSuspendedByUser = "SuspendedByUser"
+
+	// VolumePopulatorValidationRulesValid is true when all validation rules
+	// of the populator compile and are evaluated for PVCs. It is only set
+	// for populators with validationRules.
+	VolumePopulatorValidationRulesValid = "ValidationRulesValid"
+
+	// VolumePopulatorReasonRulesCompiled means all validation rules
+	// compile.
+	VolumePopulatorReasonRulesCompiled = "RulesCompiled"
+	// VolumePopulatorReasonRuleCompileError means a validation rule does
+	// not compile.
+	VolumePopulatorReasonRuleCompileError = "RuleCompileError"
+	// VolumePopulatorReasonRulesNotEnforced means the validation rules
+	// compile, but are not evaluated, because the validator runs without
+	// the ValidationRules plugin.
+	VolumePopulatorReasonRulesNotEnforced = "RulesNotEnforced"
+
+	// VolumePopulatorValidationWebhookEnabled is true when the validator
+	// calls the validation webhook of the populator for PVCs. It is only
+	// set for populators with a validationWebhook.
+	VolumePopulatorValidationWebhookEnabled = "ValidationWebhookEnabled"
+
+	// VolumePopulatorReasonWebhookEnabled means the validator calls the
+	// validation webhook.
+	VolumePopulatorReasonWebhookEnabled = "WebhookEnabled"
+	// VolumePopulatorReasonWebhookNotEnabled means the validator does not
+	// call the validation webhook, because it runs without the
+	// PopulatorWebhook plugin.
+	VolumePopulatorReasonWebhookNotEnabled = "WebhookNotEnabled"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // VolumePopulatorList is a list of VolumePopulator objects
 // +kubebuilder:object:root=true
@@ -51,3 +290,45 @@ type VolumePopulatorList struct {
 	// List of VolumePopulators
 	Items []VolumePopulator `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// NamespacedVolumePopulator represents the registration for a volume
+// populator run by a tenant. It makes its source kind valid only for PVCs in
+// its own namespace, so namespace admins can register populators without
+// cluster-wide write access. A VolumePopulator for the same source kind
+// takes precedence.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="SourceKind",type=string,JSONPath=`.sourceKind`
+// +kubebuilder:metadata:annotations="api-approved.kubernetes.io=https://github.com/kubernetes/enhancements/pull/2934"
+// +kubebuilder:metadata:annotations="populator.storage.k8s.io/bundle-version=v1.8.0"
+type NamespacedVolumePopulator struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Kind of the data source this populator supports, with the same rules
+	// as the sourceKind of VolumePopulators.
+	// +kubebuilder:validation:XValidation:rule="self.kind != ''",message="kind must not be empty"
+	// +kubebuilder:validation:XValidation:rule="self.kind == '' || self.kind.matches('^[A-Z][A-Za-z0-9]*$')",message="kind must be CamelCase, starting with an uppercase letter"
+	// +kubebuilder:validation:XValidation:rule="self.group == '' || (size(self.group) <= 253 && self.group.matches('^[a-z0-9]([-a-z0-9]*[a-z0-9])?([.][a-z0-9]([-a-z0-9]*[a-z0-9])?)*$'))",message="group must be empty or a lowercase DNS subdomain"
+	// +kubebuilder:validation:XValidation:rule="!(self.group == '' && self.kind == 'PersistentVolumeClaim')",message="PersistentVolumeClaim is handled by Kubernetes and cannot be registered"
+	// +kubebuilder:validation:XValidation:rule="!(self.group == 'snapshot.storage.k8s.io' && self.kind == 'VolumeSnapshot')",message="VolumeSnapshot is handled by Kubernetes and cannot be registered"
+	SourceKind metav1.GroupKind `json:"sourceKind" protobuf:"bytes,2,name=sourceKind"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// NamespacedVolumePopulatorList is a list of NamespacedVolumePopulator
+// objects
+// +kubebuilder:object:root=true
+type NamespacedVolumePopulatorList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// List of NamespacedVolumePopulators
+	Items []NamespacedVolumePopulator `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
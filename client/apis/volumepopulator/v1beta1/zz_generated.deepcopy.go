@@ -22,15 +22,247 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSourceReview) DeepCopyInto(out *DataSourceReview) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(DataSourceReviewRequest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(DataSourceReviewResponse)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceReview.
+func (in *DataSourceReview) DeepCopy() *DataSourceReview {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSourceReview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSourceReviewRequest) DeepCopyInto(out *DataSourceReviewRequest) {
+	*out = *in
+	in.PVC.DeepCopyInto(&out.PVC)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceReviewRequest.
+func (in *DataSourceReviewRequest) DeepCopy() *DataSourceReviewRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSourceReviewRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataSourceReviewResponse) DeepCopyInto(out *DataSourceReviewResponse) {
+	*out = *in
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataSourceReviewResponse.
+func (in *DataSourceReviewResponse) DeepCopy() *DataSourceReviewResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(DataSourceReviewResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseReference) DeepCopyInto(out *LeaseReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseReference.
+func (in *LeaseReference) DeepCopy() *LeaseReference {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedVolumePopulator) DeepCopyInto(out *NamespacedVolumePopulator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.SourceKind = in.SourceKind
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedVolumePopulator.
+func (in *NamespacedVolumePopulator) DeepCopy() *NamespacedVolumePopulator {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedVolumePopulator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedVolumePopulator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedVolumePopulatorList) DeepCopyInto(out *NamespacedVolumePopulatorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespacedVolumePopulator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedVolumePopulatorList.
+func (in *NamespacedVolumePopulatorList) DeepCopy() *NamespacedVolumePopulatorList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedVolumePopulatorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedVolumePopulatorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceReference) DeepCopyInto(out *ServiceReference) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceReference.
+func (in *ServiceReference) DeepCopy() *ServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationRule) DeepCopyInto(out *ValidationRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationRule.
+func (in *ValidationRule) DeepCopy() *ValidationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationWebhook) DeepCopyInto(out *ValidationWebhook) {
+	*out = *in
+	in.ClientConfig.DeepCopyInto(&out.ClientConfig)
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationWebhook.
+func (in *ValidationWebhook) DeepCopy() *ValidationWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumePopulator) DeepCopyInto(out *VolumePopulator) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.SourceKind = in.SourceKind
+	if in.ExcludedKinds != nil {
+		in, out := &in.ExcludedKinds, &out.ExcludedKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredVersions != nil {
+		in, out := &in.RequiredVersions, &out.RequiredVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ControllerLease != nil {
+		in, out := &in.ControllerLease, &out.ControllerLease
+		*out = new(LeaseReference)
+		**out = **in
+	}
+	if in.ValidationRules != nil {
+		in, out := &in.ValidationRules, &out.ValidationRules
+		*out = make([]ValidationRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValidationWebhook != nil {
+		in, out := &in.ValidationWebhook, &out.ValidationWebhook
+		*out = new(ValidationWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxPendingPVCsPerNamespace != nil {
+		in, out := &in.MaxPendingPVCsPerNamespace, &out.MaxPendingPVCsPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(VolumePopulatorStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumePopulator.
@@ -82,3 +314,55 @@ func (in *VolumePopulatorList) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumePopulatorStatus) DeepCopyInto(out *VolumePopulatorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumePopulatorStatus.
+func (in *VolumePopulatorStatus) DeepCopy() *VolumePopulatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumePopulatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookClientConfig) DeepCopyInto(out *WebhookClientConfig) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(string)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookClientConfig.
+func (in *WebhookClientConfig) DeepCopy() *WebhookClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
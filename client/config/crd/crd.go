@@ -0,0 +1,175 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crd embeds the CustomResourceDefinitions of VolumePopulators and
+// NamespacedVolumePopulators and installs them, so that binaries do not
+// depend on the manifests being applied by hand.
+package crd
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+// BundleVersionAnnotation is the release of the CRD, in semantic version
+// form. It is bumped with every change of the schema.
+const BundleVersionAnnotation = "populator.storage.k8s.io/bundle-version"
+
+//go:embed populator.storage.k8s.io_volumepopulators.yaml
+var volumePopulatorsManifest []byte
+
+//go:embed populator.storage.k8s.io_namespacedvolumepopulators.yaml
+var namespacedVolumePopulatorsManifest []byte
+
+// ErrNewerVersionInstalled is returned by Install when the installed CRD
+// has a newer bundle version than the embedded one.
+var ErrNewerVersionInstalled = errors.New("a newer version of the CRD is installed")
+
+// Action is what Install did.
+type Action string
+
+const (
+	// ActionCreated means the CRD was not installed.
+	ActionCreated Action = "Created"
+	// ActionUpgraded means an older CRD was replaced.
+	ActionUpgraded Action = "Upgraded"
+	// ActionUnchanged means the same version of the CRD is installed.
+	ActionUnchanged Action = "Unchanged"
+)
+
+// Client reads and writes CustomResourceDefinitions. It is implemented by
+// the dynamic client of the customresourcedefinitions resource.
+type Client interface {
+	Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error)
+	Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error)
+	Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error)
+}
+
+// VolumePopulators returns the embedded CRD of VolumePopulators.
+func VolumePopulators() (*unstructured.Unstructured, error) {
+	return decode(volumePopulatorsManifest)
+}
+
+// NamespacedVolumePopulators returns the embedded CRD of
+// NamespacedVolumePopulators.
+func NamespacedVolumePopulators() (*unstructured.Unstructured, error) {
+	return decode(namespacedVolumePopulatorsManifest)
+}
+
+// All returns all embedded CRDs.
+func All() ([]*unstructured.Unstructured, error) {
+	var crds []*unstructured.Unstructured
+	for _, manifest := range [][]byte{volumePopulatorsManifest, namespacedVolumePopulatorsManifest} {
+		crd, err := decode(manifest)
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+func decode(manifest []byte) (*unstructured.Unstructured, error) {
+	var object map[string]interface{}
+	if err := yaml.Unmarshal(manifest, &object); err != nil {
+		return nil, fmt.Errorf("failed to decode CRD: %w", err)
+	}
+	return &unstructured.Unstructured{Object: object}, nil
+}
+
+// Install creates an embedded CRD, or upgrades it if the installed one has
+// an older bundle version. CRDs without a bundle version predate it and are
+// upgraded. It refuses to downgrade a CRD installed by a newer release and
+// returns ErrNewerVersionInstalled.
+func Install(ctx context.Context, client Client, crd *unstructured.Unstructured) (Action, error) {
+	embedded, err := bundleVersion(crd)
+	if err != nil {
+		return "", fmt.Errorf("embedded CRD %s: %w", crd.GetName(), err)
+	}
+	if embedded == nil {
+		return "", fmt.Errorf("embedded CRD %s has no %s annotation", crd.GetName(), BundleVersionAnnotation)
+	}
+
+	installed, err := client.Get(ctx, crd.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := client.Create(ctx, crd, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create CRD %s: %w", crd.GetName(), err)
+		}
+		return ActionCreated, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get CRD %s: %w", crd.GetName(), err)
+	}
+
+	current, err := bundleVersion(installed)
+	if err != nil {
+		return "", fmt.Errorf("installed CRD %s: %w", crd.GetName(), err)
+	}
+	if current != nil {
+		if current.GreaterThan(embedded) {
+			return "", fmt.Errorf("%w: CRD %s is at %s, this binary has %s", ErrNewerVersionInstalled, crd.GetName(), current, embedded)
+		}
+		if current.EqualTo(embedded) {
+			return ActionUnchanged, nil
+		}
+	}
+
+	// Labels and annotations set by others, for example by kubectl, are
+	// kept.
+	upgraded := installed.DeepCopy()
+	upgraded.Object["spec"] = crd.Object["spec"]
+	upgraded.SetLabels(merge(installed.GetLabels(), crd.GetLabels()))
+	upgraded.SetAnnotations(merge(installed.GetAnnotations(), crd.GetAnnotations()))
+	if _, err := client.Update(ctx, upgraded, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to upgrade CRD %s: %w", crd.GetName(), err)
+	}
+	return ActionUpgraded, nil
+}
+
+// bundleVersion returns the bundle version of a CRD, or nil if it has none.
+func bundleVersion(crd *unstructured.Unstructured) (*version.Version, error) {
+	value, ok := crd.GetAnnotations()[BundleVersionAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	v, err := version.ParseSemantic(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %w", BundleVersionAnnotation, value, err)
+	}
+	return v, nil
+}
+
+func merge(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeClient struct {
+	crd     *unstructured.Unstructured
+	updated bool
+}
+
+func (c *fakeClient) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if c.crd == nil {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}, name)
+	}
+	return c.crd.DeepCopy(), nil
+}
+
+func (c *fakeClient) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	c.crd = obj.DeepCopy()
+	return obj, nil
+}
+
+func (c *fakeClient) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	c.crd = obj.DeepCopy()
+	c.updated = true
+	return obj, nil
+}
+
+func installedCRD(annotations map[string]string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("volumepopulators.populator.storage.k8s.io")
+	crd.SetResourceVersion("42")
+	crd.SetAnnotations(annotations)
+	crd.Object["spec"] = map[string]interface{}{"group": "populator.storage.k8s.io"}
+	return crd
+}
+
+func TestAll(t *testing.T) {
+	crds, err := All()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := []string{"volumepopulators.populator.storage.k8s.io", "namespacedvolumepopulators.populator.storage.k8s.io"}
+	if len(crds) != len(names) {
+		t.Fatalf("expected \"%v\" to equal \"%v\"", len(crds), len(names))
+	}
+	for i, crd := range crds {
+		if crd.GetName() != names[i] {
+			t.Errorf("expected \"%v\" to equal \"%v\"", crd.GetName(), names[i])
+		}
+		v, err := bundleVersion(crd)
+		if err != nil || v == nil {
+			t.Errorf("expected a bundle version of %s, got %v, %v", crd.GetName(), v, err)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	testCases := []struct {
+		name      string
+		installed *unstructured.Unstructured
+		action    Action
+		err       error
+		upgraded  bool
+	}{
+		{
+			name:   "not installed",
+			action: ActionCreated,
+		},
+		{
+			name:      "installed without bundle version",
+			installed: installedCRD(map[string]string{"owner": "admin"}),
+			action:    ActionUpgraded,
+			upgraded:  true,
+		},
+		{
+			name:      "older version installed",
+			installed: installedCRD(map[string]string{BundleVersionAnnotation: "v1.0.0"}),
+			action:    ActionUpgraded,
+			upgraded:  true,
+		},
+		{
+			name:      "newer version installed",
+			installed: installedCRD(map[string]string{BundleVersionAnnotation: "v99.0.0"}),
+			err:       ErrNewerVersionInstalled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			crd, err := VolumePopulators()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			client := &fakeClient{crd: tc.installed}
+			action, err := Install(context.TODO(), client, crd)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("expected error %v, got %v", tc.err, err)
+				}
+				if client.updated {
+					t.Errorf("expected the CRD not to be updated")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if action != tc.action {
+				t.Errorf("expected \"%v\" to equal \"%v\"", action, tc.action)
+			}
+			if client.updated != tc.upgraded {
+				t.Errorf("expected \"%v\" to equal \"%v\"", client.updated, tc.upgraded)
+			}
+			want := crd.GetAnnotations()[BundleVersionAnnotation]
+			if got := client.crd.GetAnnotations()[BundleVersionAnnotation]; got != want {
+				t.Errorf("expected \"%v\" to equal \"%v\"", got, want)
+			}
+			if tc.installed != nil {
+				if client.crd.GetResourceVersion() != "42" {
+					t.Errorf("expected the resource version to be kept, got %q", client.crd.GetResourceVersion())
+				}
+				if owner := tc.installed.GetAnnotations()["owner"]; client.crd.GetAnnotations()["owner"] != owner {
+					t.Errorf("expected \"%v\" to equal \"%v\"", client.crd.GetAnnotations()["owner"], owner)
+				}
+			}
+
+			// A second install finds the same version.
+			client.updated = false
+			action, err = Install(context.TODO(), client, crd)
+			if err != nil || action != ActionUnchanged || client.updated {
+				t.Errorf("expected an unchanged CRD, got %v, %v", action, err)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ module github.com/kubernetes-csi/volume-data-source-validator/client
 
 go 1.24.0
 
-require k8s.io/apimachinery v0.34.1
+require (
+	k8s.io/apimachinery v0.34.1
+	sigs.k8s.io/yaml v1.6.0
+)
 
 require (
 	github.com/fxamacker/cbor/v2 v2.9.0 // indirect
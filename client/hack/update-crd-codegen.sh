@@ -25,11 +25,7 @@ CONTROLLER_GEN=$(which controller-gen || true)
 
 if [ "$CONTROLLER_GEN" = "" ]
 then
-  TMP_DIR=$(mktemp -d);
-  cd $TMP_DIR;
-  go mod init tmp;
-  go get sigs.k8s.io/controller-tools/cmd/controller-gen@v0.5.0;
-  rm -rf $TMP_DIR;
+  go install sigs.k8s.io/controller-tools/cmd/controller-gen@v0.19.0;
   CONTROLLER_GEN=$(which controller-gen || true)
 fi
 
@@ -39,7 +35,8 @@ then
   exit 1;
 fi
 
-$CONTROLLER_GEN crd:crdVersions=v1,trivialVersions=true paths=${SCRIPT_ROOT}/apis/volumepopulator/v1beta1
+$CONTROLLER_GEN crd:crdVersions=v1 paths=${SCRIPT_ROOT}/apis/volumepopulator/v1beta1 \
+  output:crd:dir=${SCRIPT_ROOT}/config/crd
 
 $CONTROLLER_GEN object:headerFile=./hack/boilerplate.go.txt,year=$(date +%Y) \
   paths=${SCRIPT_ROOT}/apis/volumepopulator/v1beta1/types.go
@@ -18,30 +18,40 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	coreinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	"github.com/kubernetes-csi/csi-lib-utils/leaderelection"
 
 	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"github.com/kubernetes-csi/volume-data-source-validator/client/config/crd"
 
 	popcontroller "github.com/kubernetes-csi/volume-data-source-validator/pkg/data-source-validator"
 	"github.com/kubernetes-csi/volume-data-source-validator/pkg/metrics"
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation/grpcplugin"
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/webhook"
 )
 
 // Command line flags
@@ -56,6 +66,46 @@ var (
 	leaderElectionRenewDeadline = flag.Duration("leader-election-renew-deadline", 10*time.Second, "Duration, in seconds, that the acting leader will retry refreshing leadership before giving up. Defaults to 10 seconds.")
 	leaderElectionRetryPeriod   = flag.Duration("leader-election-retry-period", 5*time.Second, "Duration, in seconds, the LeaderElector clients should wait between tries of actions. Defaults to 5 seconds.")
 
+	sourceKindCheck = flag.Bool("source-kind-check", false, "Check that the API server serves the source kind of every VolumePopulator, using CustomResourceDefinitions and discovery. PVCs using a kind that is not served are reported with a DataSourceKindNotServed event.")
+
+	populationStallThreshold = flag.Duration("population-stall-threshold", 0, "Emit a PopulationStalled event on PVCs with a populator data source that are still Pending this long after their creation. The default 0 disables the check.")
+
+	populatorLeaseCheck = flag.Bool("populator-lease-check", false, "Watch the controllerLease of VolumePopulators and emit a PopulatorUnavailable event on Pending PVCs whose populator's lease has expired.")
+
+	autoRegisterPopulators = flag.Bool("auto-register-populators", false, "Create a VolumePopulator for every CustomResourceDefinition annotated with "+popv1beta1.PopulatorAnnotation+"=true, owned by the CRD, and delete it when the annotation is removed.")
+
+	namespacedPopulators = flag.Bool("namespaced-populators", false, "Accept data sources whose kind is registered by a NamespacedVolumePopulator in the namespace of the PVC. The NamespacedVolumePopulator CRD must be installed.")
+
+	workloadValidation = flag.Bool("workload-validation", false, "Validate the data sources of the volumeClaimTemplates of StatefulSets and of the ephemeral volumes of Pods, and emit a warning event on workloads whose PVCs would be rejected.")
+
+	defaultDataSources = flag.Bool("default-data-sources", false, "Inject the data source set by the "+popcontroller.AnnDefaultSourceKind+" and "+popcontroller.AnnDefaultSourceName+" annotations of the Namespace, or else of the StorageClass, into PVCs created without one, unless they are annotated with "+popcontroller.AnnSkipDefaultSource+"=true. Only data sources that would be accepted are injected. Requires --webhook-address and the /mutate-pvcs webhook to be registered.")
+
+	sourceAccessCheck = flag.Bool("source-access-check", false, "Reject PVCs whose creator is not allowed to get their data source, checked with a SubjectAccessReview in the /validate-pvcs webhook. Sources that cannot be checked are rejected too. Requires --webhook-address.")
+
+	webhookAddress = flag.String("webhook-address", "", "The TCP network address where the admission webhook server will listen (example: `:8443`). The default is empty string, which means the server is disabled.")
+	tlsCertFile    = flag.String("tls-cert-file", "", "File containing the x509 certificate of the admission webhook server.")
+	tlsKeyFile     = flag.String("tls-private-key-file", "", "File containing the x509 private key matching --tls-cert-file.")
+
+	sourceProtection = flag.Bool("source-protection", false, "Add the "+popcontroller.SourceProtectionFinalizer+" finalizer to the data sources of Pending PVCs and remove it once all PVCs using a source are Bound or deleted, also on startup. The validator must be allowed to get, list and patch the source objects. The finalizer is not removed once the option is turned off, see the README.")
+
+	populatorProtection = flag.Bool("populator-protection", false, "Add the "+popcontroller.PopulatorProtectionFinalizer+" finalizer to VolumePopulators and keep a deleted VolumePopulator until no Pending PVC uses its source kind, unless it is annotated with "+popcontroller.ForceDeleteAnnotation+"=true.")
+
+	provenance = flag.Bool("provenance", false, "Annotate bound PVCs with a validated data source, and their PVs, with the source kind, name and namespace, the matching VolumePopulator and the time the validator saw them bound. PVCs that were already bound when the validator first saw them are marked as backfilled instead of timestamped.")
+
+	lineagePath      = flag.String("lineage-path", "", "The HTTP path where the lineage graph of PVCs and their data sources will be served as JSON, or as Graphviz DOT with ?format=dot. The graph names the PVCs of all namespaces, so it is only served to clients on the loopback interface, for example through kubectl port-forward. Requires --http-endpoint. The default is empty string, which means the graph is not served.")
+	lineageSnapshots = flag.Bool("lineage-snapshots", false, "Follow VolumeSnapshots to the PVCs they were taken of, in the lineage graph and for --max-clone-depth.")
+	maxCloneDepth    = flag.Int("max-clone-depth", 0, "Emit a CloneChainTooDeep event on Pending PVCs that are more than this many clones away from their original volume. The default 0 disables the check.")
+
+	validatorPlugins = flag.String("validator-plugins", "", "Comma-separated list of validator plugins to run, in order, on data sources that pass the built-in checks. Available plugins: "+strings.Join(validation.DefaultRegistry.Names(), ", ")+". The default is empty string, which means no plugins are run.")
+
+	grpcPlugins             = flag.String("grpc-plugins", "", "Comma-separated list of out-of-process validator plugins, as name=socket pairs where socket is the path of the Unix domain socket the plugin serves gRPC on. They run, in order, after --validator-plugins.")
+	grpcPluginTimeout       = flag.Duration("grpc-plugin-timeout", 2*time.Second, "Timeout of calls to out-of-process validator plugins.")
+	grpcPluginFailurePolicy = flag.String("grpc-plugin-failure-policy", string(grpcplugin.FailurePolicyIgnore), "What happens when an out-of-process validator plugin cannot be called: Ignore accepts the data source, Fail rejects it with a "+grpcplugin.ReasonPluginFailed+" event.")
+
+	workloadEvents = flag.Bool("workload-events", false, "Copy warning events of PVCs to the Pods and StatefulSets that use them.")
+
+	installCRDs = flag.Bool("install-crds", false, "Create the VolumePopulator and NamespacedVolumePopulator CRDs on startup, or upgrade them if an older version is installed. CRDs installed by a newer release are not downgraded.")
+
 	httpEndpoint = flag.String("http-endpoint", "", "The TCP network address where the HTTP server for diagnostics, including metrics and leader election health check, will listen (example: `:8080`). The default is empty string, which means the server is disabled.")
 	metricsPath  = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is `/metrics`.")
 )
@@ -75,6 +125,16 @@ func main() {
 	}
 	klog.Infof("Version: %s", version)
 
+	// These options only act in the admission webhooks.
+	if *webhookAddress == "" {
+		if *sourceAccessCheck {
+			klog.Fatalf("--source-access-check requires --webhook-address")
+		}
+		if *defaultDataSources {
+			klog.Fatalf("--default-data-sources requires --webhook-address")
+		}
+	}
+
 	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
 	config, err := buildConfig(*kubeconfig)
 	if err != nil {
@@ -93,6 +153,32 @@ func main() {
 		klog.Fatalf("Failed to create dynamic client: %v", err)
 	}
 
+	if *installCRDs {
+		crds, err := crd.All()
+		if err != nil {
+			klog.Fatalf("Failed to read the embedded CRDs: %v", err)
+		}
+		for _, obj := range crds {
+			// Replicas starting together race to install the CRDs.
+			var action crd.Action
+			err := retry.OnError(retry.DefaultBackoff, func(err error) bool {
+				return apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err)
+			}, func() error {
+				var err error
+				action, err = crd.Install(context.TODO(), dynClient.Resource(popcontroller.CRDResource), obj)
+				return err
+			})
+			switch {
+			case errors.Is(err, crd.ErrNewerVersionInstalled):
+				klog.Warningf("Not installing CRD %s: %v", obj.GetName(), err)
+			case err != nil:
+				klog.Fatalf("Failed to install CRD %s: %v", obj.GetName(), err)
+			default:
+				klog.Infof("CRD %s: %s", obj.GetName(), action)
+			}
+		}
+	}
+
 	coreFactory := coreinformers.NewSharedInformerFactory(kubeClient, 0)
 	dynFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynClient, 0)
 
@@ -113,14 +199,126 @@ func main() {
 
 	klog.V(2).Infof("Start NewDataSourceValidator with kubeconfig [%s]", *kubeconfig)
 
+	// Unknown kinds run discovery again at most once per
+	// DefaultMapperResetInterval, as they are looked up for any PVC or
+	// admission request.
+	mapper := validation.NewRefreshingRESTMapper(
+		restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(kubeClient.Discovery())),
+		validation.DefaultMapperResetInterval,
+	)
+
+	var opts []popcontroller.Option
+	if *populatorLeaseCheck {
+		opts = append(opts, popcontroller.WithPopulatorLeases(kubeClient))
+	}
+	if *workloadEvents {
+		opts = append(opts, popcontroller.WithWorkloadEvents(
+			coreFactory.Core().V1().Pods(),
+			coreFactory.Apps().V1().StatefulSets(),
+		))
+	}
+	if *workloadValidation {
+		opts = append(opts, popcontroller.WithWorkloadValidation(
+			coreFactory.Core().V1().Pods(),
+			coreFactory.Apps().V1().StatefulSets(),
+		))
+	}
+	if *sourceProtection {
+		opts = append(opts, popcontroller.WithSourceProtection(mapper))
+	}
+	if *sourceAccessCheck {
+		opts = append(opts, popcontroller.WithSourceAccessCheck(mapper))
+	}
+	if *validatorPlugins != "" {
+		plugins, err := validation.DefaultRegistry.Build(strings.Split(*validatorPlugins, ","), validation.Handle{
+			Client:        kubeClient,
+			DynamicClient: dynClient,
+			Mapper:        mapper,
+		})
+		if err != nil {
+			klog.Fatalf("Failed to create validator plugins: %v", err)
+		}
+		opts = append(opts, popcontroller.WithValidatorPlugins(plugins))
+	}
+	if *grpcPlugins != "" {
+		var plugins []validation.Plugin
+		for _, endpoint := range strings.Split(*grpcPlugins, ",") {
+			name, socket, found := strings.Cut(endpoint, "=")
+			if !found {
+				klog.Fatalf("Invalid gRPC plugin %q, expected name=socket", endpoint)
+			}
+			plugin, err := grpcplugin.New(grpcplugin.Config{
+				Name:          name,
+				Socket:        socket,
+				Timeout:       *grpcPluginTimeout,
+				FailurePolicy: grpcplugin.FailurePolicy(*grpcPluginFailurePolicy),
+			}, metricsManager)
+			if err != nil {
+				klog.Fatalf("Failed to create gRPC plugin: %v", err)
+			}
+			plugins = append(plugins, plugin)
+		}
+		opts = append(opts, popcontroller.WithValidatorPlugins(plugins))
+	}
+	if *populatorProtection {
+		opts = append(opts, popcontroller.WithPopulatorProtection())
+	}
+	if *provenance {
+		opts = append(opts, popcontroller.WithProvenance())
+	}
+	if *lineageSnapshots {
+		opts = append(opts, popcontroller.WithSnapshotLineage(
+			dynFactory.ForResource(popcontroller.SnapshotResource).Informer(),
+		))
+	}
+	if *maxCloneDepth > 0 {
+		opts = append(opts, popcontroller.WithMaxCloneDepth(*maxCloneDepth))
+	}
+	if *populationStallThreshold > 0 {
+		opts = append(opts, popcontroller.WithPopulationStallThreshold(*populationStallThreshold))
+	}
+	if *sourceKindCheck {
+		opts = append(opts, popcontroller.WithSourceKindCheck(
+			dynFactory.ForResource(popcontroller.CRDResource).Informer(),
+			kubeClient.Discovery(),
+		))
+	}
+	if *namespacedPopulators {
+		opts = append(opts, popcontroller.WithNamespacedPopulators(
+			dynFactory.ForResource(popcontroller.NamespacedPopulatorResource).Informer(),
+		))
+	}
+	if *defaultDataSources {
+		opts = append(opts, popcontroller.WithDefaultDataSources(
+			coreFactory.Core().V1().Namespaces(),
+			coreFactory.Storage().V1().StorageClasses(),
+		))
+	}
+	if *autoRegisterPopulators {
+		opts = append(opts, popcontroller.WithAutoRegistration(
+			dynFactory.ForResource(popcontroller.CRDResource).Informer(),
+			dynFactory.ForResource(popcontroller.PopulatorResource).Informer(),
+		))
+	}
+
 	ctrl := popcontroller.NewDataSourceValidator(
 		dynClient,
 		kubeClient,
 		dynFactory.ForResource(popcontroller.PopulatorResource).Informer(),
 		coreFactory.Core().V1().PersistentVolumeClaims(),
 		metricsManager,
+		opts...,
 	)
 
+	if *httpEndpoint != "" && *lineagePath != "" {
+		mux.Handle(*lineagePath, ctrl.LineageHandler())
+		klog.Infof("Lineage graph path successfully registered at %s", *lineagePath)
+	}
+
+	// The process runs until SIGINT.
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stopSignals()
+
 	run := func(context.Context) {
 		// run...
 		stopCh := make(chan struct{})
@@ -129,9 +327,7 @@ func main() {
 		go ctrl.Run(*threads, stopCh)
 
 		// ...until SIGINT
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt)
-		<-c
+		<-signalCtx.Done()
 		close(stopCh)
 	}
 
@@ -161,6 +357,36 @@ func main() {
 		}()
 	}
 
+	// The webhooks are served by all replicas, not only by the leader, so
+	// the informers are started before leader election. They stop with the
+	// process.
+	if *webhookAddress != "" {
+		srv, err := webhook.NewServer(*tlsCertFile, *tlsKeyFile)
+		if err != nil {
+			klog.Fatalf("Failed to create webhook server: %v", err)
+		}
+		srv.Handle("/validate-workloads", webhook.AdmitWorkloads(ctrl))
+		srv.Handle("/validate-populators", webhook.AdmitPopulators(ctrl))
+		// Population quotas are set on VolumePopulators at any time, so
+		// PVCs are always validated; the source access check is optional.
+		srv.Handle("/validate-pvcs", webhook.AdmitPVCs(ctrl))
+		if *defaultDataSources {
+			srv.Handle("/mutate-pvcs", webhook.MutatePVCs(ctrl))
+		}
+
+		webhookStopCh := signalCtx.Done()
+		dynFactory.Start(webhookStopCh)
+		coreFactory.Start(webhookStopCh)
+		if !ctrl.WaitForCacheSync(webhookStopCh) {
+			klog.Fatalf("Cannot sync caches for the webhook server")
+		}
+		go func() {
+			if err := srv.Run(*webhookAddress, webhookStopCh); err != nil {
+				klog.Fatalf("Failed to run webhook server: %v", err)
+			}
+		}()
+	}
+
 	if !*leaderElection {
 		run(context.TODO())
 	} else {
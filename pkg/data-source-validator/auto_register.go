@@ -0,0 +1,241 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"fmt"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// crdKind is the kind of the owner of auto-registered VolumePopulators.
+const crdKind = "CustomResourceDefinition"
+
+// WithAutoRegistration makes the controller create a VolumePopulator for
+// every CustomResourceDefinition annotated with
+// popv1beta1.PopulatorAnnotation=true. The registration has the name of the
+// CRD and an owner reference to it, so it is garbage-collected with the CRD.
+// It is deleted when the annotation is removed, and re-created when deleted
+// while the annotation is set. The informers must not be started yet.
+func WithAutoRegistration(crdInformer, volumePopulatorInformer cache.SharedIndexInformer) Option {
+	return func(ctrl *populatorController) {
+		ctrl.registrationQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "registration")
+		crdInformer.AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    ctrl.enqueueRegistration,
+				UpdateFunc: func(oldObj, newObj interface{}) { ctrl.enqueueRegistration(newObj) },
+				DeleteFunc: ctrl.enqueueRegistration,
+			},
+		)
+		// Registrations owned by a CRD are re-created or reverted when
+		// changed by someone else.
+		enqueueOwner := func(obj interface{}) {
+			if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+				obj = unknown.Obj
+			}
+			if populator, ok := obj.(metav1.Object); ok {
+				if owner := crdOwner(populator); owner != nil {
+					ctrl.registrationQueue.Add(owner.Name)
+				}
+			}
+		}
+		volumePopulatorInformer.AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    enqueueOwner,
+				UpdateFunc: func(oldObj, newObj interface{}) { enqueueOwner(newObj) },
+				DeleteFunc: enqueueOwner,
+			},
+		)
+		ctrl.registrationCRDs = crdInformer.GetIndexer()
+		ctrl.registrationCRDsSynced = crdInformer.HasSynced
+	}
+}
+
+// enqueueRegistration adds a CRD to the registration queue.
+func (ctrl *populatorController) enqueueRegistration(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("failed to get key from object: %v, %v", err, obj)
+		return
+	}
+	klog.V(5).Infof("enqueued CRD %q for registration", key)
+	ctrl.registrationQueue.Add(key)
+}
+
+// registrationWorker is the main worker for CRD registrations.
+func (ctrl *populatorController) registrationWorker() {
+	keyObj, quit := ctrl.registrationQueue.Get()
+	if quit {
+		return
+	}
+	defer ctrl.registrationQueue.Done(keyObj)
+
+	if err := ctrl.syncRegistrationByKey(keyObj.(string)); err != nil {
+		ctrl.registrationQueue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to sync registration of CRD %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		ctrl.registrationQueue.Forget(keyObj)
+	}
+}
+
+// syncRegistrationByKey creates, updates or deletes the VolumePopulator of a
+// CRD, which has the name of the CRD.
+func (ctrl *populatorController) syncRegistrationByKey(name string) error {
+	klog.V(5).Infof("syncRegistrationByKey[%s]", name)
+
+	obj, exists, err := ctrl.registrationCRDs.GetByKey(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// The garbage collector deletes the registration of a deleted
+		// CRD.
+		return nil
+	}
+	crd := obj.(*unstructured.Unstructured)
+
+	existing, err := ctrl.popLister.Get(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		existing = nil
+	}
+	owned := existing != nil && ownedBy(existing, crd)
+
+	if crd.GetDeletionTimestamp() != nil || crd.GetAnnotations()[popv1beta1.PopulatorAnnotation] != "true" {
+		if owned {
+			return ctrl.deleteRegistration(existing)
+		}
+		return nil
+	}
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	sourceKind := metav1.GroupKind{Group: group, Kind: kind}
+
+	if existing == nil {
+		populators, err := ctrl.listPopulators()
+		if err != nil {
+			return err
+		}
+		for _, populator := range populators {
+			if populator.SourceKind == sourceKind {
+				klog.V(2).Infof("Not registering CRD %q, VolumePopulator %q already registers %s", name, populator.Name, sourceKind.String())
+				return nil
+			}
+		}
+		return ctrl.createRegistration(crd, sourceKind)
+	}
+	if !owned {
+		klog.V(2).Infof("Not registering CRD %q, VolumePopulator %q is not owned by it", name, existing.GetName())
+		return nil
+	}
+
+	populator, err := validation.ConvertPopulator(existing)
+	if err != nil {
+		return err
+	}
+	if populator.SourceKind == sourceKind {
+		return nil
+	}
+	existing = existing.DeepCopy()
+	if err := unstructured.SetNestedStringMap(existing.Object, map[string]string{"group": group, "kind": kind}, "sourceKind"); err != nil {
+		return err
+	}
+	_, err = ctrl.dynClient.Resource(PopulatorResource).Update(context.TODO(), existing, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update populator %q: %w", name, err)
+	}
+	klog.V(2).Infof("Updated populator %q of CRD %q to %s", name, name, sourceKind.String())
+	return nil
+}
+
+// createRegistration creates the VolumePopulator of a CRD.
+func (ctrl *populatorController) createRegistration(crd *unstructured.Unstructured, sourceKind metav1.GroupKind) error {
+	populator := &popv1beta1.VolumePopulator{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: popv1beta1.SchemeGroupVersion.String(),
+			Kind:       "VolumePopulator",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: crd.GetName(),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: CRDResource.GroupVersion().String(),
+				Kind:       crdKind,
+				Name:       crd.GetName(),
+				UID:        crd.GetUID(),
+				Controller: ptr.To(true),
+			}},
+		},
+		SourceKind: sourceKind,
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(populator)
+	if err != nil {
+		return err
+	}
+	_, err = ctrl.dynClient.Resource(PopulatorResource).Create(context.TODO(), &unstructured.Unstructured{Object: content}, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			// The informer has not seen it yet, the CRD is enqueued
+			// again when it does.
+			return nil
+		}
+		return fmt.Errorf("failed to create populator %q: %w", populator.Name, err)
+	}
+	klog.V(2).Infof("Registered %s of CRD %q", sourceKind.String(), crd.GetName())
+	return nil
+}
+
+// deleteRegistration deletes the VolumePopulator of a CRD.
+func (ctrl *populatorController) deleteRegistration(populator *unstructured.Unstructured) error {
+	err := ctrl.dynClient.Resource(PopulatorResource).Delete(context.TODO(), populator.GetName(), metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: ptr.To(populator.GetUID())},
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete populator %q: %w", populator.GetName(), err)
+	}
+	klog.V(2).Infof("Deleted populator %q, its CRD is no longer annotated with %s=true", populator.GetName(), popv1beta1.PopulatorAnnotation)
+	return nil
+}
+
+// crdOwner returns the owner reference of a populator to its CRD, or nil if
+// it was not auto-registered.
+func crdOwner(populator metav1.Object) *metav1.OwnerReference {
+	owner := metav1.GetControllerOfNoCopy(populator)
+	if owner == nil || owner.Kind != crdKind || owner.APIVersion != CRDResource.GroupVersion().String() {
+		return nil
+	}
+	return owner
+}
+
+// ownedBy returns whether a populator was auto-registered for a CRD.
+func ownedBy(populator, crd metav1.Object) bool {
+	owner := crdOwner(populator)
+	return owner != nil && owner.Name == crd.GetName() && owner.UID == crd.GetUID()
+}
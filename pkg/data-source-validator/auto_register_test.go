@@ -0,0 +1,156 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"testing"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+const registeredCRD = "Image.images.storage.k8s.io"
+
+func makeAnnotatedCRD(annotated bool) *unstructured.Unstructured {
+	crd := makeCRD("images.storage.k8s.io", "Image", true, map[string]bool{"v1": true})
+	crd.SetUID("crd-uid")
+	if annotated {
+		crd.SetAnnotations(map[string]string{popv1beta1.PopulatorAnnotation: "true"})
+	}
+	return crd
+}
+
+func makeRegisteredPopulator(group, kind string) *popv1beta1.VolumePopulator {
+	populator := makePopulator(registeredCRD, group, kind)
+	populator.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apiextensions.k8s.io/v1",
+		Kind:       "CustomResourceDefinition",
+		Name:       registeredCRD,
+		UID:        types.UID("crd-uid"),
+		Controller: ptr.To(true),
+	}}
+	return populator
+}
+
+func TestSyncRegistration(t *testing.T) {
+	testCases := []struct {
+		name       string
+		annotated  bool
+		populators []*popv1beta1.VolumePopulator
+		// sourceKind is the expected source kind of the registration,
+		// empty if it must not exist.
+		sourceKind string
+		owned      bool
+	}{
+		{
+			name:       "Registration created",
+			annotated:  true,
+			sourceKind: "Image.images.storage.k8s.io",
+			owned:      true,
+		},
+		{
+			name:       "Registration updated",
+			annotated:  true,
+			populators: []*popv1beta1.VolumePopulator{makeRegisteredPopulator("old.storage.k8s.io", "Image")},
+			sourceKind: "Image.images.storage.k8s.io",
+			owned:      true,
+		},
+		{
+			name:       "Registration deleted without annotation",
+			populators: []*popv1beta1.VolumePopulator{makeRegisteredPopulator("images.storage.k8s.io", "Image")},
+		},
+		{
+			name:       "Manual registration kept without annotation",
+			populators: []*popv1beta1.VolumePopulator{makePopulator(registeredCRD, "images.storage.k8s.io", "Image")},
+			sourceKind: "Image.images.storage.k8s.io",
+		},
+		{
+			name:       "Manual registration with the same name kept",
+			annotated:  true,
+			populators: []*popv1beta1.VolumePopulator{makePopulator(registeredCRD, "other.storage.k8s.io", "Other")},
+			sourceKind: "Other.other.storage.k8s.io",
+		},
+		{
+			name:       "Kind registered by another populator",
+			annotated:  true,
+			populators: []*popv1beta1.VolumePopulator{makePopulator("manual", "images.storage.k8s.io", "Image")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, lister := makeFakeClient(tc.populators...)
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			indexer.Add(makeAnnotatedCRD(tc.annotated))
+			ctrl := &populatorController{
+				dynClient:        client,
+				popLister:        lister,
+				registrationCRDs: indexer,
+			}
+
+			if err := ctrl.syncRegistrationByKey(registeredCRD); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+
+			unstPopulator, err := client.Resource(PopulatorResource).Get(context.TODO(), registeredCRD, metav1.GetOptions{})
+			if tc.sourceKind == "" {
+				if !errors.IsNotFound(err) {
+					t.Errorf(`expected no registration, got "%v"`, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			populator, err := validation.ConvertPopulator(unstPopulator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if sourceKind := populator.SourceKind.String(); sourceKind != tc.sourceKind {
+				t.Errorf(`expected "%v" to equal "%v"`, sourceKind, tc.sourceKind)
+			}
+			if owned := ownedBy(populator, makeAnnotatedCRD(true)); owned != tc.owned {
+				t.Errorf(`expected "%v" to equal "%v"`, owned, tc.owned)
+			}
+		})
+	}
+}
+
+func TestSyncRegistrationDeletedCRD(t *testing.T) {
+	client, lister := makeFakeClient(makeRegisteredPopulator("images.storage.k8s.io", "Image"))
+	ctrl := &populatorController{
+		dynClient:        client,
+		popLister:        lister,
+		registrationCRDs: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+	}
+
+	// The garbage collector deletes the registration.
+	if err := ctrl.syncRegistrationByKey(registeredCRD); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if _, err := client.Resource(PopulatorResource).Get(context.TODO(), registeredCRD, metav1.GetOptions{}); err != nil {
+		t.Errorf(`expected nil error, got "%v"`, err)
+	}
+}
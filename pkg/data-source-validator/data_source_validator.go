@@ -18,29 +18,37 @@ package data_source_validator
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamiclister"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	"github.com/kubernetes-csi/volume-data-source-validator/pkg/metrics"
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
 )
 
 type populatorController struct {
@@ -48,20 +56,113 @@ type populatorController struct {
 	client        kubernetes.Interface
 	eventRecorder record.EventRecorder
 	queue         workqueue.RateLimitingInterface
+	popQueue      workqueue.RateLimitingInterface
+	// workloadQueue is set when the PVC templates of workloads are
+	// validated.
+	workloadQueue workqueue.RateLimitingInterface
+	// sourceQueue is set when data sources are protected from deletion.
+	sourceQueue workqueue.RateLimitingInterface
+	// registrationQueue is set when VolumePopulators are registered for
+	// annotated CRDs.
+	registrationQueue workqueue.RateLimitingInterface
 
 	popLister       dynamiclister.Lister
 	popListerSynced cache.InformerSynced
 	pvcLister       corelisters.PersistentVolumeClaimLister
+	pvcIndexer      cache.Indexer
 	pvcListerSynced cache.InformerSynced
 
+	// populatorCRDMissing is set while the controller runs degraded,
+	// because VolumePopulators are not served.
+	populatorCRDMissing atomic.Bool
+	populatorCRDOnce    sync.Once
+
+	// nsPopLister is set when NamespacedVolumePopulators are accepted.
+	nsPopLister       dynamiclister.Lister
+	nsPopListerSynced cache.InformerSynced
+
+	// crdIndexer is set when source kinds of populators are checked
+	// against the installed CRDs.
+	crdIndexer      cache.Indexer
+	crdListerSynced cache.InformerSynced
+	discovery       discovery.CachedDiscoveryInterface
+
+	// registrationCRDs is set when VolumePopulators are registered for
+	// annotated CRDs.
+	registrationCRDs       cache.Indexer
+	registrationCRDsSynced cache.InformerSynced
+
+	// mapper is set when data sources are protected from deletion or the
+	// access of PVC creators to them is checked.
+	mapper meta.RESTMapper
+	// sourceAccessCheck is set when the access of PVC creators to their
+	// data sources is checked.
+	sourceAccessCheck bool
+
+	populations    *populationTracker
+	stallThreshold time.Duration
+
+	// snapshotLister is set when VolumeSnapshots are followed to their
+	// source PVCs.
+	snapshotLister       dynamiclister.Lister
+	snapshotListerSynced cache.InformerSynced
+	maxCloneDepth        int
+
+	// provenance is set when bound PVCs and their PVs are annotated with
+	// their data source. pendingClaims holds the UIDs of the PVCs seen
+	// Pending, keyed by namespace/name, whose binding is timestamped.
+	provenance        bool
+	pendingClaims     map[string]types.UID
+	pendingClaimsLock sync.Mutex
+
+	// plugins run after the built-in checks of data sources.
+	plugins []validation.Plugin
+
+	// populatorProtection is set when VolumePopulators in use are
+	// protected from deletion.
+	populatorProtection bool
+
+	// leases is set when the controller leases of populators are watched.
+	leases *leaseWatcher
+
+	// podIndexer and ssLister are set when PVC events are copied to
+	// workloads, podLister and ssLister when the PVC templates of workloads
+	// are validated.
+	podIndexer      cache.Indexer
+	podLister       corelisters.PodLister
+	podListerSynced cache.InformerSynced
+	ssLister        appslisters.StatefulSetLister
+	ssListerSynced  cache.InformerSynced
+
+	// namespaceLister and storageClassLister are set when default data
+	// sources are injected into PVCs.
+	namespaceLister          corelisters.NamespaceLister
+	namespaceListerSynced    cache.InformerSynced
+	storageClassLister       storagelisters.StorageClassLister
+	storageClassListerSynced cache.InformerSynced
+
 	metrics metrics.MetricsManager
 }
 
+// Option configures optional behavior of the controller.
+type Option func(ctrl *populatorController)
+
+// WithValidatorPlugins makes the controller run the given plugins, in
+// order, after the built-in checks of data sources. Plugins of several
+// WithValidatorPlugins options run in the order of the options.
+func WithValidatorPlugins(plugins []validation.Plugin) Option {
+	return func(ctrl *populatorController) {
+		ctrl.plugins = append(ctrl.plugins, plugins...)
+	}
+}
+
 var (
 	pvcGK            = metav1.GroupKind{Group: v1.GroupName, Kind: "PersistentVolumeClaim"}
 	volumeSnapshotGK = metav1.GroupKind{Group: volumesnapshotv1.GroupName, Kind: "VolumeSnapshot"}
 
-	PopulatorResource = popv1beta1.SchemeGroupVersion.WithResource("volumepopulators")
+	PopulatorResource           = popv1beta1.SchemeGroupVersion.WithResource("volumepopulators")
+	NamespacedPopulatorResource = popv1beta1.SchemeGroupVersion.WithResource("namespacedvolumepopulators")
+	CRDResource                 = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
 )
 
 func NewDataSourceValidator(
@@ -70,6 +171,7 @@ func NewDataSourceValidator(
 	volumePopulatorInformer cache.SharedIndexInformer,
 	pvcInformer coreinformers.PersistentVolumeClaimInformer,
 	metrics metrics.MetricsManager,
+	opts ...Option,
 ) *populatorController {
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartLogging(klog.Infof)
@@ -82,7 +184,9 @@ func NewDataSourceValidator(
 		client:        client,
 		eventRecorder: eventRecorder,
 		metrics:       metrics,
+		populations:   newPopulationTracker(),
 		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "pvc"),
+		popQueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "volumepopulator"),
 	}
 
 	pvcInformer.Informer().AddEventHandler(
@@ -93,32 +197,110 @@ func NewDataSourceValidator(
 		},
 	)
 	ctrl.pvcLister = pvcInformer.Lister()
+	ctrl.pvcIndexer = pvcInformer.Informer().GetIndexer()
 	ctrl.pvcListerSynced = pvcInformer.Informer().HasSynced
 
+	// Any change to one registration can start or end a conflict with the
+	// others, so every populator is re-evaluated. Changes and deletions can
+	// also leave a namespace of leases unreferenced.
+	volumePopulatorInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { ctrl.enqueueAllPopulators() },
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				ctrl.enqueueAllPopulators()
+				ctrl.pruneLeaseWatches()
+			},
+			DeleteFunc: func(obj interface{}) {
+				ctrl.enqueueAllPopulators()
+				ctrl.pruneLeaseWatches()
+			},
+		},
+	)
 	ctrl.popLister = dynamiclister.New(volumePopulatorInformer.GetIndexer(), PopulatorResource)
 	ctrl.popListerSynced = volumePopulatorInformer.HasSynced
 
+	for _, opt := range opts {
+		opt(ctrl)
+	}
+
 	return ctrl
 }
 
 func (ctrl *populatorController) Run(workers int, stopCh <-chan struct{}) {
 	defer ctrl.queue.ShutDown()
+	defer ctrl.popQueue.ShutDown()
+	if ctrl.workloadQueue != nil {
+		defer ctrl.workloadQueue.ShutDown()
+	}
+	if ctrl.sourceQueue != nil {
+		defer ctrl.sourceQueue.ShutDown()
+	}
+	if ctrl.registrationQueue != nil {
+		defer ctrl.registrationQueue.ShutDown()
+	}
 
 	klog.Infof("Starting volume-data-source-validator controller")
 	defer klog.Infof("Shutting down volume-data-source-validator controller")
 
-	if !cache.WaitForCacheSync(stopCh, ctrl.popListerSynced, ctrl.pvcListerSynced) {
+	if !ctrl.WaitForCacheSync(stopCh) {
 		klog.Errorf("Cannot sync caches")
 		return
 	}
+	if ctrl.leases != nil {
+		ctrl.leases.start(stopCh)
+	}
+	if ctrl.sourceQueue != nil {
+		go ctrl.enqueueProtectedSources()
+	}
 
 	for i := 0; i < workers; i++ {
 		go wait.Until(ctrl.worker, 0, stopCh)
+		go wait.Until(ctrl.popWorker, 0, stopCh)
+		if ctrl.workloadQueue != nil {
+			go wait.Until(ctrl.workloadWorker, 0, stopCh)
+		}
+		if ctrl.sourceQueue != nil {
+			go wait.Until(ctrl.sourceWorker, 0, stopCh)
+		}
+		if ctrl.registrationQueue != nil {
+			go wait.Until(ctrl.registrationWorker, 0, stopCh)
+		}
 	}
 
 	<-stopCh
 }
 
+// WaitForCacheSync waits until the informers used by the controller have
+// synced. The informers must have been started. If the VolumePopulator CRD
+// is not installed, it does not wait for VolumePopulators and the
+// controller runs degraded until the CRD appears.
+func (ctrl *populatorController) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	ctrl.populatorCRDOnce.Do(func() { ctrl.checkPopulatorCRD(stopCh) })
+	synced := []cache.InformerSynced{ctrl.pvcListerSynced}
+	if !ctrl.populatorCRDMissing.Load() {
+		synced = append(synced, ctrl.popListerSynced)
+	}
+	if ctrl.nsPopListerSynced != nil {
+		synced = append(synced, ctrl.nsPopListerSynced)
+	}
+	if ctrl.crdListerSynced != nil {
+		synced = append(synced, ctrl.crdListerSynced)
+	}
+	if ctrl.registrationCRDsSynced != nil {
+		synced = append(synced, ctrl.registrationCRDsSynced)
+	}
+	if ctrl.podListerSynced != nil {
+		synced = append(synced, ctrl.podListerSynced, ctrl.ssListerSynced)
+	}
+	if ctrl.snapshotListerSynced != nil {
+		synced = append(synced, ctrl.snapshotListerSynced)
+	}
+	if ctrl.namespaceListerSynced != nil {
+		synced = append(synced, ctrl.namespaceListerSynced, ctrl.storageClassListerSynced)
+	}
+	return cache.WaitForCacheSync(stopCh, synced...)
+}
+
 // enqueueWork adds PVC to given work queue.
 func (ctrl *populatorController) enqueueWork(obj interface{}) {
 	// Beware of "xxx deleted" events
@@ -133,6 +315,12 @@ func (ctrl *populatorController) enqueueWork(obj interface{}) {
 		}
 		klog.V(5).Infof("enqueued %q for sync", objName)
 		ctrl.queue.Add(objName)
+		if ctrl.sourceQueue != nil {
+			ctrl.enqueueSource(pvc)
+		}
+		if ctrl.populatorProtection && pvc.Spec.DataSourceRef != nil {
+			ctrl.enqueueDeletingPopulators(dataSourceGroupKind(pvc))
+		}
 	}
 }
 
@@ -170,75 +358,159 @@ func (ctrl *populatorController) syncPvcByKey(key string) error {
 	if err != nil {
 		if errors.IsNotFound(err) {
 			utilruntime.HandleError(fmt.Errorf("pvc '%s' in work queue no longer exists", key))
+			ctrl.forgetPendingClaim(key)
+			if p := ctrl.forgetPopulation(key); p != nil {
+				return ctrl.refreshPopulationQuota(namespace, p.sourceKind)
+			}
 			return nil
 		}
 		klog.V(2).Infof("error getting pvc %q from informer: %v", key, err)
 		return err
 	}
 
-	dataSourceRef := pvc.Spec.DataSourceRef
-	if dataSourceRef == nil {
+	if pvc.Spec.DataSourceRef == nil {
 		// No data source
 		ctrl.metrics.IncrementCount(metrics.DataSourceEmptyResultName)
 		return nil
 	}
-	apiGroup := ""
-	if dataSourceRef.APIGroup != nil {
-		apiGroup = *dataSourceRef.APIGroup
-	}
-
-	gk := metav1.GroupKind{
-		Group: apiGroup,
-		Kind:  dataSourceRef.Kind,
-	}
+	gk := dataSourceGroupKind(pvc)
 	klog.V(3).Infof("PVC %q datasource is %q", pvc.Name, gk.String())
+	if ctrl.skipWhileDegraded(gk) {
+		// The PVC is enqueued again when the CRD is installed.
+		klog.V(4).Infof("Skipping PVC %q until the VolumePopulator CRD is installed", key)
+		return nil
+	}
 
-	valid, err := ctrl.validateGroupKind(gk)
+	result, err := ctrl.validatePVC(pvc)
 	if err != nil {
 		return err
 	}
 
-	if !valid {
-		ctrl.eventRecorder.Event(pvc, v1.EventTypeWarning, "UnrecognizedDataSourceKind",
-			"The datasource for this PVC does not match any registered VolumePopulator")
+	ctrl.reportFindings(pvc, result.Findings)
+	if !result.Valid && len(result.Findings) == 0 {
+		ctrl.pvcWarning(pvc, result.Reason, result.Message)
+	}
+
+	if result.Valid && ctrl.provenance {
+		if err := ctrl.recordProvenance(key, pvc, result); err != nil {
+			return err
+		}
+	}
+
+	if result.Valid && ctrl.maxCloneDepth > 0 && pvc.Status.Phase == v1.ClaimPending && (gk == pvcGK || gk == volumeSnapshotGK) {
+		depth, err := ctrl.cloneDepth(pvc)
+		if err != nil {
+			return err
+		}
+		if depth > ctrl.maxCloneDepth {
+			klog.V(2).Infof("PVC %q is %d clones deep", key, depth)
+			ctrl.pvcWarning(pvc, "CloneChainTooDeep",
+				fmt.Sprintf("The PVC is %d clones away from its original volume, more than the maximum of %d", depth, ctrl.maxCloneDepth))
+		}
+	}
+
+	if !result.Valid || result.Populator == nil {
+		ctrl.forgetPopulation(key)
+		return nil
+	}
+	ctrl.trackPopulation(key, pvc, gk, result.Populator.Name)
+
+	if result.Populator.MaxPendingPVCsPerNamespace != nil {
+		if err := ctrl.checkPopulationQuota(pvc, gk, result.Populator); err != nil {
+			return err
+		}
+	}
+
+	if pvc.Status.Phase == v1.ClaimPending {
+		available, _, message, _, err := ctrl.populatorAvailable(result.Populator)
+		if err != nil {
+			return err
+		}
+		if !available {
+			ctrl.pvcWarning(pvc, "PopulatorUnavailable",
+				fmt.Sprintf("%s for %s is unavailable: %s", validation.DescribePopulator(result.Populator), gk.String(), message))
+		}
 	}
 
 	return nil
 }
 
-func (ctrl *populatorController) validateGroupKind(gk metav1.GroupKind) (bool, error) {
-	// Cloning PVCs and Volume Snapshots are special cases, allowed by the
-	// core, so don't reject these.
-	switch gk {
-	case pvcGK:
-		ctrl.metrics.IncrementCount(metrics.DataSourcePVCResultName)
-		klog.V(4).Infof("Allowing PVC as a special case")
-		return true, nil
-	case volumeSnapshotGK:
-		ctrl.metrics.IncrementCount(metrics.DataSourceSnapshotResultName)
-		klog.V(4).Infof("Allowing VolumeSnapshot as a special case")
-		return true, nil
-	}
-	unstPopulators, err := ctrl.popLister.List(labels.Everything())
+// pluginEnabled returns whether the controller runs the plugin with the
+// given name.
+func (ctrl *populatorController) pluginEnabled(name string) bool {
+	for _, plugin := range ctrl.plugins {
+		if plugin.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validator returns the validation library configured like the controller.
+func (ctrl *populatorController) validator() validation.Validator {
+	opts := []validation.Option{validation.WithPlugins(ctrl.plugins...)}
+	if ctrl.crdIndexer != nil {
+		opts = append(opts, validation.WithSourceKindChecker(sourceKindChecker{ctrl: ctrl}))
+	}
+	if ctrl.nsPopLister != nil {
+		opts = append(opts, validation.WithNamespacedPopulators(validation.NewDynamicNamespacedPopulatorLister(ctrl.nsPopLister)))
+	}
+	return validation.New(validation.NewDynamicPopulatorLister(ctrl.popLister), opts...)
+}
+
+// validatePVC validates the data source of a PVC and counts the result.
+func (ctrl *populatorController) validatePVC(pvc *v1.PersistentVolumeClaim) (validation.Result, error) {
+	return ctrl.countResult(ctrl.validator().ValidatePVC(pvc))
+}
+
+// validateSpec validates the data source of a PVC spec and counts the
+// result.
+func (ctrl *populatorController) validateSpec(namespace string, spec *v1.PersistentVolumeClaimSpec) (validation.Result, error) {
+	return ctrl.countResult(ctrl.validator().ValidateSpec(namespace, spec))
+}
+
+func (ctrl *populatorController) countResult(result validation.Result, err error) (validation.Result, error) {
 	if err != nil {
-		klog.Errorf("Failed to list populators: %v", err)
 		ctrl.metrics.IncrementCount(metrics.DataSourceErrorResultName)
-		return false, err
+		return result, err
 	}
-	for _, unstPopulator := range unstPopulators {
-		var populator popv1beta1.VolumePopulator
-		err = runtime.DefaultUnstructuredConverter.FromUnstructured(unstPopulator.UnstructuredContent(), &populator)
-		if err != nil {
-			ctrl.metrics.IncrementCount(metrics.DataSourceErrorResultName)
-			return false, err
-		}
-		if populator.SourceKind == gk {
-			ctrl.metrics.IncrementCount(metrics.DataSourcePopulatorResultName)
-			klog.V(4).Infof("Allowing %q due to %q populator", gk.String(), populator.Name)
-			return true, nil
+	ctrl.metrics.IncrementCount(resultName(result))
+	return result, nil
+}
+
+// reportFindings emits an event on the PVC for each plugin finding and
+// counts them.
+func (ctrl *populatorController) reportFindings(pvc *v1.PersistentVolumeClaim, findings []validation.Finding) {
+	for _, finding := range findings {
+		ctrl.metrics.IncrementPluginFinding(finding.Plugin, string(finding.Severity))
+		if finding.Severity == validation.SeverityInfo {
+			ctrl.eventRecorder.Event(pvc, v1.EventTypeNormal, finding.Reason, finding.Message)
+		} else {
+			ctrl.pvcWarning(pvc, finding.Reason, finding.Message)
 		}
 	}
-	ctrl.metrics.IncrementCount(metrics.DataSourceInvalidResultName)
-	klog.Warningf("No populator matches %s", gk.String())
-	return false, nil
+}
+
+// resultName returns the metrics result name of a validation result.
+func resultName(result validation.Result) string {
+	switch {
+	case result.Reason == validation.ReasonDataSourceKindNotServed:
+		return metrics.DataSourceNotServedResultName
+	case !result.Valid:
+		return metrics.DataSourceInvalidResultName
+	case result.Source == validation.SourcePersistentVolumeClaim:
+		return metrics.DataSourcePVCResultName
+	case result.Source == validation.SourceVolumeSnapshot:
+		return metrics.DataSourceSnapshotResultName
+	case result.Source == validation.SourcePopulator || result.Source == validation.SourceNamespacedPopulator:
+		return metrics.DataSourcePopulatorResultName
+	default:
+		return metrics.DataSourceEmptyResultName
+	}
+}
+
+// listPopulators returns all VolumePopulators known to the informer, sorted
+// by name so that callers see them in a stable order.
+func (ctrl *populatorController) listPopulators() ([]*popv1beta1.VolumePopulator, error) {
+	return validation.NewDynamicPopulatorLister(ctrl.popLister).List()
 }
@@ -20,16 +20,23 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/dynamic/dynamiclister"
 	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	k8smetrics "k8s.io/component-base/metrics"
 
 	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
@@ -37,15 +44,38 @@ import (
 	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
 )
 
-type FakeMetricsManager struct{}
+type FakeMetricsManager struct {
+	populationDurations []string
+	// quotaUsage is the pending count by namespace/source kind.
+	quotaUsage map[string]int
+}
 
 func (*FakeMetricsManager) PrepareMetricsPath(mux *http.ServeMux, pattern string, logger promhttp.Logger) error {
 	return nil
 }
-func (*FakeMetricsManager) IncrementCount(result string)         {}
+func (*FakeMetricsManager) IncrementCount(result string)                   {}
+func (*FakeMetricsManager) SetPopulatorConflicts(count int)                {}
+func (*FakeMetricsManager) SetPopulatorCRDMissing(missing bool)            {}
+func (*FakeMetricsManager) IncrementPluginFinding(plugin, severity string) {}
+func (*FakeMetricsManager) RecordPluginCall(plugin string, duration time.Duration, err error) {
+}
+func (m *FakeMetricsManager) RecordPopulationDuration(sourceKind string, duration time.Duration) {
+	m.populationDurations = append(m.populationDurations, sourceKind)
+}
+func (m *FakeMetricsManager) SetPopulationQuotaUsage(namespace, sourceKind string, pending, limit int) {
+	if m.quotaUsage == nil {
+		m.quotaUsage = make(map[string]int)
+	}
+	m.quotaUsage[namespace+"/"+sourceKind] = pending
+}
 func (*FakeMetricsManager) GetRegistry() k8smetrics.KubeRegistry { return nil }
 
 func makeFakeLister(populators ...*popv1beta1.VolumePopulator) dynamiclister.Lister {
+	_, lister := makeFakeClient(populators...)
+	return lister
+}
+
+func makeFakeClient(populators ...*popv1beta1.VolumePopulator) (dynamic.Interface, dynamiclister.Lister) {
 	scheme := runtime.NewScheme()
 	popv1beta1.AddToScheme(scheme)
 	objects := make([]runtime.Object, len(populators))
@@ -59,7 +89,39 @@ func makeFakeLister(populators ...*popv1beta1.VolumePopulator) dynamiclister.Lis
 	stopCh := make(chan struct{})
 	factory.Start(stopCh)
 	cache.WaitForCacheSync(stopCh, informer.HasSynced)
-	return lister
+	return client, lister
+}
+
+// makeTestController returns a controller with a fake client serving the
+// given PVCs through a synced informer, the given populators, a fake
+// recorder and a queue. Tests set the other fields and options they need.
+func makeTestController(t *testing.T, pvcs []*v1.PersistentVolumeClaim, populators ...*popv1beta1.VolumePopulator) *populatorController {
+	var objects []runtime.Object
+	for _, pvc := range pvcs {
+		objects = append(objects, pvc)
+	}
+	client := kubefake.NewSimpleClientset(objects...)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	pvcInformer := factory.Core().V1().PersistentVolumeClaims()
+	pvcInformer.Informer()
+
+	ctrl := &populatorController{
+		client:          client,
+		eventRecorder:   record.NewFakeRecorder(10),
+		metrics:         new(FakeMetricsManager),
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "pvc"),
+		popLister:       makeFakeLister(populators...),
+		pvcLister:       pvcInformer.Lister(),
+		pvcListerSynced: pvcInformer.Informer().HasSynced,
+		populations:     newPopulationTracker(),
+	}
+	t.Cleanup(ctrl.queue.ShutDown)
+
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, ctrl.pvcListerSynced)
+	return ctrl
 }
 
 type brokenVolumeLister struct {
@@ -77,6 +139,16 @@ func (*brokenVolumeLister) Namespace(string) dynamiclister.NamespaceLister {
 	return nil
 }
 
+func dataSourceSpec(gk metav1.GroupKind) *v1.PersistentVolumeClaimSpec {
+	return &v1.PersistentVolumeClaimSpec{
+		DataSourceRef: &v1.TypedObjectReference{
+			APIGroup: &gk.Group,
+			Kind:     gk.Kind,
+			Name:     "source",
+		},
+	}
+}
+
 func TestValidateGroupKind(t *testing.T) {
 	ctrl := new(populatorController)
 	ctrl.metrics = new(FakeMetricsManager)
@@ -137,9 +209,9 @@ func TestValidateGroupKind(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			valid, err := ctrl.validateGroupKind(tc.gk)
-			if valid != tc.valid {
-				t.Errorf(`expected "%v" to equal "%v"`, valid, tc.valid)
+			result, err := ctrl.validateSpec("default", dataSourceSpec(tc.gk))
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
 			}
 			if err != nil {
 				t.Errorf(`expected nil error, got "%v"`, err)
@@ -153,11 +225,11 @@ func TestPopListError(t *testing.T) {
 	ctrl.metrics = new(FakeMetricsManager)
 	ctrl.popLister = new(brokenVolumeLister)
 
-	valid, err := ctrl.validateGroupKind(metav1.GroupKind{
+	result, err := ctrl.validateSpec("default", dataSourceSpec(metav1.GroupKind{
 		Group: "valid.storage.k8s.io",
 		Kind:  "Valid",
-	})
-	if valid {
+	}))
+	if result.Valid {
 		t.Error("expected invalid")
 	}
 	if nil == err {
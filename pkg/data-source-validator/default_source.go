@@ -0,0 +1,151 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	storageinformers "k8s.io/client-go/informers/storage/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// Default data source annotations. The policy annotations are set on
+// Namespaces and StorageClasses, the others on PVCs.
+const (
+	// AnnDefaultSourceKind is the GroupKind of the data source injected
+	// into PVCs without one, in Kind.group form.
+	AnnDefaultSourceKind = "datasource-validator.storage.k8s.io/default-source-kind"
+	// AnnDefaultSourceName is the name of the data source injected into
+	// PVCs without one. The source is in the namespace of the PVC.
+	AnnDefaultSourceName = "datasource-validator.storage.k8s.io/default-source-name"
+	// AnnSkipDefaultSource opts a PVC out of default data sources when set
+	// to "true".
+	AnnSkipDefaultSource = "datasource-validator.storage.k8s.io/skip-default-source"
+	// AnnInjectedSource is the injected data source, in Kind.group/name
+	// form.
+	AnnInjectedSource = "datasource-validator.storage.k8s.io/injected-source"
+	// AnnInjectedSourcePolicy is the object whose policy injected the data
+	// source, Namespace/<name> or StorageClass/<name>.
+	AnnInjectedSourcePolicy = "datasource-validator.storage.k8s.io/injected-source-policy"
+)
+
+// WithDefaultDataSources makes the controller inject the default data
+// source of the namespace or StorageClass of a PVC into PVCs created
+// without one. The policy of the namespace takes precedence. The informers
+// must not be started yet.
+func WithDefaultDataSources(namespaceInformer coreinformers.NamespaceInformer, storageClassInformer storageinformers.StorageClassInformer) Option {
+	return func(ctrl *populatorController) {
+		ctrl.namespaceLister = namespaceInformer.Lister()
+		ctrl.namespaceListerSynced = namespaceInformer.Informer().HasSynced
+		ctrl.storageClassLister = storageClassInformer.Lister()
+		ctrl.storageClassListerSynced = storageClassInformer.Informer().HasSynced
+	}
+}
+
+// defaultSourcePolicy is the default data source set by a Namespace or a
+// StorageClass.
+type defaultSourcePolicy struct {
+	// object is the Namespace or StorageClass, in Kind/name form.
+	object      string
+	annotations map[string]string
+}
+
+// DefaultDataSource injects the default data source of its namespace or
+// StorageClass into a PVC that is being created without a data source, and
+// records it in the AnnInjectedSource and AnnInjectedSourcePolicy
+// annotations. A data source is only injected if it would be accepted;
+// otherwise the PVC is left unchanged and a warning explains why.
+func (ctrl *populatorController) DefaultDataSource(pvc *v1.PersistentVolumeClaim) ([]string, error) {
+	if ctrl.namespaceLister == nil || pvc.Spec.DataSource != nil || pvc.Spec.DataSourceRef != nil ||
+		pvc.Annotations[AnnSkipDefaultSource] == "true" {
+		return nil, nil
+	}
+
+	policy, err := ctrl.defaultSourcePolicy(pvc)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+	kind, name := policy.annotations[AnnDefaultSourceKind], policy.annotations[AnnDefaultSourceName]
+	if kind == "" || name == "" {
+		return []string{fmt.Sprintf("The default data source of %s was not injected: both %s and %s must be set",
+			policy.object, AnnDefaultSourceKind, AnnDefaultSourceName)}, nil
+	}
+
+	gk := schema.ParseGroupKind(kind)
+	dataSourceRef := &v1.TypedObjectReference{Kind: gk.Kind, Name: name}
+	if gk.Group != "" {
+		dataSourceRef.APIGroup = &gk.Group
+	}
+	spec := pvc.Spec.DeepCopy()
+	spec.DataSourceRef = dataSourceRef
+	result, err := ctrl.validator().ValidateSpec(pvc.Namespace, spec)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid {
+		klog.V(2).Infof("Not injecting data source %s/%s of %s into PVC %s/%s: %s", kind, name, policy.object, pvc.Namespace, pvc.Name, result.Message)
+		return []string{fmt.Sprintf("The default data source %s/%s of %s was not injected: %s", kind, name, policy.object, result.Message)}, nil
+	}
+
+	pvc.Spec.DataSourceRef = dataSourceRef
+	sourceKind := validation.DataSourceGroupKind(dataSourceRef)
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	pvc.Annotations[AnnInjectedSource] = sourceKind.String() + "/" + name
+	pvc.Annotations[AnnInjectedSourcePolicy] = policy.object
+	klog.V(4).Infof("Injecting data source %s of %s into PVC %s/%s", pvc.Annotations[AnnInjectedSource], policy.object, pvc.Namespace, pvc.Name)
+	return nil, nil
+}
+
+// defaultSourcePolicy returns the policy that applies to a PVC: the one of
+// its namespace, or else the one of its StorageClass. It returns nil if
+// neither sets a default data source.
+func (ctrl *populatorController) defaultSourcePolicy(pvc *v1.PersistentVolumeClaim) (*defaultSourcePolicy, error) {
+	namespace, err := ctrl.namespaceLister.Get(pvc.Namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil && hasDefaultSource(namespace.Annotations) {
+		return &defaultSourcePolicy{object: "Namespace/" + namespace.Name, annotations: namespace.Annotations}, nil
+	}
+
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return nil, nil
+	}
+	storageClass, err := ctrl.storageClassLister.Get(*pvc.Spec.StorageClassName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if hasDefaultSource(storageClass.Annotations) {
+		return &defaultSourcePolicy{object: "StorageClass/" + storageClass.Name, annotations: storageClass.Annotations}, nil
+	}
+	return nil, nil
+}
+
+func hasDefaultSource(annotations map[string]string) bool {
+	return annotations[AnnDefaultSourceKind] != "" || annotations[AnnDefaultSourceName] != ""
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+func defaultSourceAnnotations(kind, name string) map[string]string {
+	return map[string]string{
+		AnnDefaultSourceKind: kind,
+		AnnDefaultSourceName: name,
+	}
+}
+
+func TestDefaultDataSource(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		namespaceAnnotations    map[string]string
+		storageClassAnnotations map[string]string
+		pvc                     *v1.PersistentVolumeClaim
+		dataSourceRef           *v1.TypedObjectReference
+		annotations             map[string]string
+		warnings                []string
+	}{
+		{
+			name:                 "Namespace policy",
+			namespaceAnnotations: defaultSourceAnnotations("Valid.valid.storage.k8s.io", "golden"),
+			pvc:                  makePVC("claim", 0, ""),
+			dataSourceRef:        &v1.TypedObjectReference{APIGroup: ptr.To("valid.storage.k8s.io"), Kind: "Valid", Name: "golden"},
+			annotations: map[string]string{
+				AnnInjectedSource:       "Valid.valid.storage.k8s.io/golden",
+				AnnInjectedSourcePolicy: "Namespace/default",
+			},
+		},
+		{
+			name:                    "StorageClass policy",
+			storageClassAnnotations: defaultSourceAnnotations("PersistentVolumeClaim", "golden"),
+			pvc:                     makePVC("claim", 0, ""),
+			dataSourceRef:           &v1.TypedObjectReference{Kind: "PersistentVolumeClaim", Name: "golden"},
+			annotations: map[string]string{
+				AnnInjectedSource:       "PersistentVolumeClaim/golden",
+				AnnInjectedSourcePolicy: "StorageClass/standard",
+			},
+		},
+		{
+			name:                    "Namespace policy takes precedence",
+			namespaceAnnotations:    defaultSourceAnnotations("Valid.valid.storage.k8s.io", "golden"),
+			storageClassAnnotations: defaultSourceAnnotations("PersistentVolumeClaim", "golden"),
+			pvc:                     makePVC("claim", 0, ""),
+			dataSourceRef:           &v1.TypedObjectReference{APIGroup: ptr.To("valid.storage.k8s.io"), Kind: "Valid", Name: "golden"},
+			annotations: map[string]string{
+				AnnInjectedSource:       "Valid.valid.storage.k8s.io/golden",
+				AnnInjectedSourcePolicy: "Namespace/default",
+			},
+		},
+		{
+			name:                 "Unregistered kind",
+			namespaceAnnotations: defaultSourceAnnotations("Invalid.invalid.storage.k8s.io", "golden"),
+			pvc:                  makePVC("claim", 0, ""),
+			warnings:             []string{"The default data source Invalid.invalid.storage.k8s.io/golden of Namespace/default was not injected: The datasource for this PVC does not match any registered VolumePopulator"},
+		},
+		{
+			name:                 "Incomplete policy",
+			namespaceAnnotations: map[string]string{AnnDefaultSourceKind: "Valid.valid.storage.k8s.io"},
+			pvc:                  makePVC("claim", 0, ""),
+			warnings:             []string{"The default data source of Namespace/default was not injected: both " + AnnDefaultSourceKind + " and " + AnnDefaultSourceName + " must be set"},
+		},
+		{
+			name:                 "Opted out",
+			namespaceAnnotations: defaultSourceAnnotations("Valid.valid.storage.k8s.io", "golden"),
+			pvc: func() *v1.PersistentVolumeClaim {
+				pvc := makePVC("claim", 0, "")
+				pvc.Annotations = map[string]string{AnnSkipDefaultSource: "true"}
+				return pvc
+			}(),
+			annotations: map[string]string{AnnSkipDefaultSource: "true"},
+		},
+		{
+			name:                 "Data source set",
+			namespaceAnnotations: defaultSourceAnnotations("Valid.valid.storage.k8s.io", "golden"),
+			pvc:                  makeChildPVC("claim", "", "PersistentVolumeClaim", "original"),
+			dataSourceRef:        &v1.TypedObjectReference{APIGroup: ptr.To(""), Kind: "PersistentVolumeClaim", Name: "original"},
+		},
+		{
+			name: "No policy",
+			pvc:  makePVC("claim", 0, ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(
+				&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: tc.namespaceAnnotations}},
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard", Annotations: tc.storageClassAnnotations}},
+			)
+			factory := informers.NewSharedInformerFactory(client, 0)
+			ctrl := &populatorController{
+				eventRecorder: record.NewFakeRecorder(10),
+				metrics:       new(FakeMetricsManager),
+				popLister:     makeFakeLister(makePopulator("valid", "valid.storage.k8s.io", "Valid")),
+			}
+			WithDefaultDataSources(factory.Core().V1().Namespaces(), factory.Storage().V1().StorageClasses())(ctrl)
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			factory.Start(stopCh)
+			cache.WaitForCacheSync(stopCh, ctrl.namespaceListerSynced, ctrl.storageClassListerSynced)
+
+			pvc := tc.pvc.DeepCopy()
+			pvc.Spec.StorageClassName = ptr.To("standard")
+			warnings, err := ctrl.DefaultDataSource(pvc)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if !reflect.DeepEqual(warnings, tc.warnings) {
+				t.Errorf(`expected "%v" to equal "%v"`, strings.Join(warnings, "; "), strings.Join(tc.warnings, "; "))
+			}
+			if !reflect.DeepEqual(pvc.Spec.DataSourceRef, tc.dataSourceRef) {
+				t.Errorf(`expected "%v" to equal "%v"`, pvc.Spec.DataSourceRef, tc.dataSourceRef)
+			}
+			if !reflect.DeepEqual(pvc.Annotations, tc.annotations) {
+				t.Errorf(`expected "%v" to equal "%v"`, pvc.Annotations, tc.annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// checkPopulatorCRD makes the controller run degraded if the API server does
+// not serve VolumePopulators. While degraded, only PersistentVolumeClaim and
+// VolumeSnapshot data sources are validated, and the controller does not
+// wait for the populator informer, which syncs once the CRD is installed.
+func (ctrl *populatorController) checkPopulatorCRD(stopCh <-chan struct{}) {
+	served, err := ctrl.populatorsServed()
+	if err != nil {
+		// Waiting for the informer is the safe choice.
+		klog.Errorf("Failed to check if VolumePopulators are served: %v", err)
+		served = true
+	}
+	ctrl.metrics.SetPopulatorCRDMissing(!served)
+	if served {
+		return
+	}
+
+	klog.Warningf("The VolumePopulator CRD %s is not installed, only %s and %s data sources are validated until it is",
+		PopulatorResource.GroupResource().String(), pvcGK.String(), volumeSnapshotGK.String())
+	ctrl.populatorCRDMissing.Store(true)
+	go func() {
+		if !cache.WaitForCacheSync(stopCh, ctrl.popListerSynced) {
+			return
+		}
+		klog.Infof("The VolumePopulator CRD is installed, validating all data sources")
+		ctrl.populatorCRDMissing.Store(false)
+		ctrl.metrics.SetPopulatorCRDMissing(false)
+		ctrl.enqueueSkippedPVCs()
+	}()
+}
+
+// populatorsServed returns whether the API server serves VolumePopulators.
+func (ctrl *populatorController) populatorsServed() (bool, error) {
+	resources, err := ctrl.client.Discovery().ServerResourcesForGroupVersion(PopulatorResource.GroupVersion().String())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == PopulatorResource.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// skipWhileDegraded returns whether a data source kind cannot be validated
+// because the VolumePopulator CRD is missing.
+func (ctrl *populatorController) skipWhileDegraded(gk metav1.GroupKind) bool {
+	return ctrl.populatorCRDMissing.Load() && gk != pvcGK && gk != volumeSnapshotGK
+}
+
+// enqueueSkippedPVCs enqueues the PVCs with a data source that was skipped
+// while the controller ran degraded.
+func (ctrl *populatorController) enqueueSkippedPVCs() {
+	pvcs, err := ctrl.pvcLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list PVCs: %v", err)
+		return
+	}
+	for _, pvc := range pvcs {
+		if pvc.Spec.DataSourceRef == nil {
+			continue
+		}
+		if gk := dataSourceGroupKind(pvc); gk != pvcGK && gk != volumeSnapshotGK {
+			ctrl.enqueueWork(pvc)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDegradedMode(t *testing.T) {
+	pvcs := []*v1.PersistentVolumeClaim{
+		makeChildPVC("populated", "valid.storage.k8s.io", "Valid", "image"),
+		makeChildPVC("clone", "", "PersistentVolumeClaim", "original"),
+	}
+	var populatorsSynced atomic.Bool
+	ctrl := makeTestController(t, pvcs)
+	ctrl.popListerSynced = populatorsSynced.Load
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if !ctrl.WaitForCacheSync(stopCh) {
+		t.Fatalf("expected caches to sync without VolumePopulators")
+	}
+	if !ctrl.populatorCRDMissing.Load() {
+		t.Fatalf("expected the controller to run degraded")
+	}
+	if !ctrl.skipWhileDegraded(metav1.GroupKind{Group: "valid.storage.k8s.io", Kind: "Valid"}) {
+		t.Errorf("expected populator kinds to be skipped")
+	}
+	if ctrl.skipWhileDegraded(pvcGK) || ctrl.skipWhileDegraded(volumeSnapshotGK) {
+		t.Errorf("expected built-in kinds to be validated")
+	}
+
+	if err := ctrl.syncPvcByKey("default/populated"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case event := <-ctrl.eventRecorder.(*record.FakeRecorder).Events:
+		t.Errorf("expected no event, got %q", event)
+	default:
+	}
+
+	// The CRD is installed and the populator informer syncs.
+	populatorsSynced.Store(true)
+	err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return !ctrl.populatorCRDMissing.Load() && ctrl.queue.Len() == 1, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the controller to leave degraded mode: %v", err)
+	}
+	key, _ := ctrl.queue.Get()
+	if key.(string) != "default/populated" {
+		t.Errorf("expected \"%v\" to equal \"%v\"", key, "default/populated")
+	}
+}
+
+func TestNotDegraded(t *testing.T) {
+	var populatorsSynced atomic.Bool
+	populatorsSynced.Store(true)
+	ctrl := makeTestController(t, nil)
+	ctrl.popListerSynced = populatorsSynced.Load
+	ctrl.client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: PopulatorResource.GroupVersion().String(),
+		APIResources: []metav1.APIResource{{Name: PopulatorResource.Resource}},
+	}}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if !ctrl.WaitForCacheSync(stopCh) {
+		t.Fatalf("expected caches to sync")
+	}
+	if ctrl.populatorCRDMissing.Load() {
+		t.Errorf("expected the controller not to run degraded")
+	}
+	if ctrl.skipWhileDegraded(metav1.GroupKind{Group: "valid.storage.k8s.io", Kind: "Valid"}) {
+		t.Errorf("expected populator kinds to be validated")
+	}
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	coordinationlisters "k8s.io/client-go/listers/coordination/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// leaseWatcher watches the Leases referenced by VolumePopulators. Leases
+// are watched per namespace and only in namespaces that populators refer
+// to, so that busy namespaces like kube-node-lease are not watched unless
+// asked for.
+type leaseWatcher struct {
+	client   kubernetes.Interface
+	onChange func(namespace, name string)
+
+	lock    sync.Mutex
+	stopCh  <-chan struct{}
+	listers map[string]coordinationlisters.LeaseLister
+	synced  map[string]cache.InformerSynced
+	// stops stops the informer of a namespace.
+	stops map[string]chan struct{}
+}
+
+// leaseSyncTimeout is how long a worker waits for the leases of a namespace
+// that was not watched before. Namespaces whose leases cannot be listed,
+// for example because they do not exist or RBAC forbids it, never sync, and
+// must not block the workers.
+var leaseSyncTimeout = 2 * time.Second
+
+// WithPopulatorLeases makes the controller watch the controller leases of
+// VolumePopulators and report populators whose lease expired.
+func WithPopulatorLeases(client kubernetes.Interface) Option {
+	return func(ctrl *populatorController) {
+		ctrl.leases = &leaseWatcher{
+			client:   client,
+			onChange: ctrl.enqueueLeasePopulators,
+			listers:  make(map[string]coordinationlisters.LeaseLister),
+			synced:   make(map[string]cache.InformerSynced),
+			stops:    make(map[string]chan struct{}),
+		}
+	}
+}
+
+// start allows the watcher to start informers, which run until stopCh is
+// closed.
+func (w *leaseWatcher) start(stopCh <-chan struct{}) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.stopCh = stopCh
+}
+
+// get returns the Lease with the given namespace and name, starting to
+// watch the namespace on first use. It waits at most leaseSyncTimeout for
+// the leases of the namespace and returns an error if they have not synced
+// by then, so that the caller is retried later.
+func (w *leaseWatcher) get(namespace, name string) (*coordinationv1.Lease, error) {
+	w.lock.Lock()
+	lister, ok := w.listers[namespace]
+	if !ok {
+		if w.stopCh == nil {
+			w.lock.Unlock()
+			return nil, fmt.Errorf("lease watcher is not started")
+		}
+		klog.V(4).Infof("Starting to watch leases in namespace %q", namespace)
+		factory := informers.NewSharedInformerFactoryWithOptions(w.client, 0, informers.WithNamespace(namespace))
+		informer := factory.Coordination().V1().Leases()
+		informer.Informer().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    w.leaseChanged,
+				UpdateFunc: func(oldObj, newObj interface{}) { w.leaseChanged(newObj) },
+				DeleteFunc: w.leaseChanged,
+			},
+		)
+		lister = informer.Lister()
+		w.listers[namespace] = lister
+		w.synced[namespace] = informer.Informer().HasSynced
+		stop := make(chan struct{})
+		w.stops[namespace] = stop
+		go func(stopCh <-chan struct{}) {
+			// The informer stops with the watcher or when the namespace
+			// is no longer watched.
+			select {
+			case <-stopCh:
+			case <-stop:
+				return
+			}
+			w.lock.Lock()
+			defer w.lock.Unlock()
+			if w.stops[namespace] == stop {
+				close(stop)
+				delete(w.stops, namespace)
+			}
+		}(w.stopCh)
+		factory.Start(stop)
+	}
+	synced := w.synced[namespace]
+	w.lock.Unlock()
+
+	if !synced() {
+		timeout := make(chan struct{})
+		timer := time.AfterFunc(leaseSyncTimeout, func() { close(timeout) })
+		defer timer.Stop()
+		if !cache.WaitForCacheSync(timeout, synced) {
+			return nil, fmt.Errorf("leases in namespace %q are not synced yet", namespace)
+		}
+	}
+	return lister.Leases(namespace).Get(name)
+}
+
+// retain stops watching the leases of namespaces that are not in the given
+// set.
+func (w *leaseWatcher) retain(namespaces sets.Set[string]) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for namespace := range w.listers {
+		if namespaces.Has(namespace) {
+			continue
+		}
+		klog.V(4).Infof("Stopping to watch leases in namespace %q", namespace)
+		if stop, ok := w.stops[namespace]; ok {
+			close(stop)
+			delete(w.stops, namespace)
+		}
+		delete(w.listers, namespace)
+		delete(w.synced, namespace)
+	}
+}
+
+func (w *leaseWatcher) leaseChanged(obj interface{}) {
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	if lease, ok := obj.(*coordinationv1.Lease); ok {
+		w.onChange(lease.Namespace, lease.Name)
+	}
+}
+
+// enqueueLeasePopulators queues the VolumePopulators whose controller lease
+// is the given Lease.
+func (ctrl *populatorController) enqueueLeasePopulators(namespace, name string) {
+	populators, err := ctrl.listPopulators()
+	if err != nil {
+		klog.Errorf("failed to list populators: %v", err)
+		return
+	}
+	for _, populator := range populators {
+		ref := populator.ControllerLease
+		if ref != nil && ref.Namespace == namespace && ref.Name == name {
+			ctrl.popQueue.Add(populator.Name)
+		}
+	}
+}
+
+// pruneLeaseWatches stops watching the leases of namespaces that no
+// VolumePopulator refers to anymore.
+func (ctrl *populatorController) pruneLeaseWatches() {
+	if ctrl.leases == nil {
+		return
+	}
+	populators, err := ctrl.listPopulators()
+	if err != nil {
+		klog.Errorf("failed to list populators: %v", err)
+		return
+	}
+	namespaces := sets.New[string]()
+	for _, populator := range populators {
+		if populator.ControllerLease != nil {
+			namespaces.Insert(populator.ControllerLease.Namespace)
+		}
+	}
+	ctrl.leases.retain(namespaces)
+}
+
+// leaseExpiry returns when the lease expires. Leases without a holder, a
+// renew time or a duration are considered expired.
+func leaseExpiry(lease *coordinationv1.Lease) time.Time {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" || lease.Spec.LeaseDurationSeconds == nil {
+		return time.Time{}
+	}
+	renewTime := lease.Spec.RenewTime
+	if renewTime == nil {
+		renewTime = lease.Spec.AcquireTime
+	}
+	if renewTime == nil {
+		return time.Time{}
+	}
+	return renewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+}
+
+// populatorAvailable checks the controller lease of a populator. It returns
+// the reason and message for the Available condition and, for held leases,
+// how long until the lease expires. Populators without a controller lease
+// are always available.
+func (ctrl *populatorController) populatorAvailable(populator *popv1beta1.VolumePopulator) (bool, string, string, time.Duration, error) {
+	ref := populator.ControllerLease
+	if ctrl.leases == nil || ref == nil {
+		return true, "", "", 0, nil
+	}
+	lease, err := ctrl.leases.get(ref.Namespace, ref.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, popv1beta1.VolumePopulatorReasonLeaseNotFound,
+				fmt.Sprintf("Lease %s/%s does not exist", ref.Namespace, ref.Name), 0, nil
+		}
+		return false, "", "", 0, err
+	}
+	remaining := time.Until(leaseExpiry(lease))
+	if remaining <= 0 {
+		return false, popv1beta1.VolumePopulatorReasonLeaseExpired,
+			fmt.Sprintf("Lease %s/%s has expired", ref.Namespace, ref.Name), 0, nil
+	}
+	return true, popv1beta1.VolumePopulatorReasonLeaseHeld,
+		fmt.Sprintf("Lease %s/%s is held by %s", ref.Namespace, ref.Name, *lease.Spec.HolderIdentity), remaining, nil
+}
+
+// availableCondition builds the Available condition of a populator with a
+// controller lease. When the lease is held, the populator is queued again
+// for when the lease would expire.
+func (ctrl *populatorController) availableCondition(populator *popv1beta1.VolumePopulator) (metav1.Condition, error) {
+	available, reason, message, remaining, err := ctrl.populatorAvailable(populator)
+	if err != nil {
+		return metav1.Condition{}, err
+	}
+	status := metav1.ConditionTrue
+	if available {
+		// A second of slack, so that the lease has certainly expired
+		// unless it was renewed.
+		ctrl.popQueue.AddAfter(populator.Name, remaining+time.Second)
+	} else {
+		status = metav1.ConditionFalse
+	}
+	return metav1.Condition{
+		Type:               popv1beta1.VolumePopulatorAvailable,
+		Status:             status,
+		ObservedGeneration: populator.Generation,
+		Reason:             reason,
+		Message:            message,
+	}, nil
+}
+
+// enqueuePendingPVCs queues all Pending PVCs that use the given source kind,
+// so that they are checked again. A kind of popv1beta1.WildcardKind matches
+// all kinds of its group.
+func (ctrl *populatorController) enqueuePendingPVCs(gk metav1.GroupKind) {
+	pvcs, err := ctrl.pvcLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pvcs: %v", err)
+		return
+	}
+	for _, pvc := range pvcs {
+		if pvc.Status.Phase != v1.ClaimPending {
+			continue
+		}
+		kind := dataSourceGroupKind(pvc)
+		if kind != gk && (gk.Kind != popv1beta1.WildcardKind || kind.Group != gk.Group) {
+			continue
+		}
+		ctrl.enqueueWork(pvc)
+	}
+}
+
+// dataSourceGroupKind returns the GroupKind of the dataSourceRef of a PVC,
+// or an empty GroupKind when it has none.
+func dataSourceGroupKind(pvc *v1.PersistentVolumeClaim) metav1.GroupKind {
+	if pvc.Spec.DataSourceRef == nil {
+		return metav1.GroupKind{}
+	}
+	return validation.DataSourceGroupKind(pvc.Spec.DataSourceRef)
+}
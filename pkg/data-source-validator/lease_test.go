@@ -0,0 +1,187 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+)
+
+func makeLease(name, holder string, renewed time.Duration) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "populators",
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(holder),
+			LeaseDurationSeconds: ptr.To[int32](15),
+			RenewTime:            ptr.To(metav1.NewMicroTime(time.Now().Add(-renewed))),
+		},
+	}
+}
+
+func TestPopulatorAvailable(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		makeLease("held", "populator-0", 5*time.Second),
+		makeLease("expired", "populator-0", time.Minute),
+		makeLease("released", "", 5*time.Second),
+	)
+	ctrl := &populatorController{
+		popLister: makeFakeLister(),
+		popQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "volumepopulator"),
+	}
+	defer ctrl.popQueue.ShutDown()
+	WithPopulatorLeases(client)(ctrl)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	ctrl.leases.start(stopCh)
+
+	testCases := []struct {
+		name      string
+		lease     *popv1beta1.LeaseReference
+		available bool
+		reason    string
+	}{
+		{
+			name:      "No lease",
+			available: true,
+		},
+		{
+			name:      "Lease held",
+			lease:     &popv1beta1.LeaseReference{Namespace: "populators", Name: "held"},
+			available: true,
+			reason:    popv1beta1.VolumePopulatorReasonLeaseHeld,
+		},
+		{
+			name:      "Lease expired",
+			lease:     &popv1beta1.LeaseReference{Namespace: "populators", Name: "expired"},
+			available: false,
+			reason:    popv1beta1.VolumePopulatorReasonLeaseExpired,
+		},
+		{
+			name:      "Lease released",
+			lease:     &popv1beta1.LeaseReference{Namespace: "populators", Name: "released"},
+			available: false,
+			reason:    popv1beta1.VolumePopulatorReasonLeaseExpired,
+		},
+		{
+			name:      "Lease missing",
+			lease:     &popv1beta1.LeaseReference{Namespace: "populators", Name: "missing"},
+			available: false,
+			reason:    popv1beta1.VolumePopulatorReasonLeaseNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			populator := makePopulator("populator", "valid.storage.k8s.io", "Valid")
+			populator.ControllerLease = tc.lease
+			available, reason, _, _, err := ctrl.populatorAvailable(populator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if available != tc.available {
+				t.Errorf(`expected "%v" to equal "%v"`, available, tc.available)
+			}
+			if reason != tc.reason {
+				t.Errorf(`expected "%v" to equal "%v"`, reason, tc.reason)
+			}
+		})
+	}
+}
+
+func TestLeaseWatcherNotSynced(t *testing.T) {
+	defer func(timeout time.Duration) { leaseSyncTimeout = timeout }(leaseSyncTimeout)
+	leaseSyncTimeout = 100 * time.Millisecond
+
+	client := fake.NewSimpleClientset(makeLease("held", "populator-0", 5*time.Second))
+	client.PrependReactor("list", "leases", func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "forbidden" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, "", nil)
+		}
+		return false, nil, nil
+	})
+	ctrl := &populatorController{
+		popLister: makeFakeLister(),
+		popQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "volumepopulator"),
+	}
+	defer ctrl.popQueue.ShutDown()
+	WithPopulatorLeases(client)(ctrl)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	ctrl.leases.start(stopCh)
+
+	// A namespace that never syncs does not block the caller.
+	start := time.Now()
+	if _, err := ctrl.leases.get("forbidden", "held"); err == nil {
+		t.Errorf("expected an error for leases that are not synced")
+	}
+	if elapsed := time.Since(start); elapsed > 10*leaseSyncTimeout {
+		t.Errorf("expected get to return after %v, took %v", leaseSyncTimeout, elapsed)
+	}
+	if _, err := ctrl.leases.get("populators", "held"); err != nil {
+		t.Errorf(`expected nil error, got "%v"`, err)
+	}
+}
+
+func TestPruneLeaseWatches(t *testing.T) {
+	client := fake.NewSimpleClientset(makeLease("held", "populator-0", 5*time.Second))
+	populator := makePopulator("populator", "valid.storage.k8s.io", "Valid")
+	populator.ControllerLease = &popv1beta1.LeaseReference{Namespace: "populators", Name: "held"}
+	ctrl := &populatorController{
+		popLister: makeFakeLister(populator),
+		popQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "volumepopulator"),
+	}
+	defer ctrl.popQueue.ShutDown()
+	WithPopulatorLeases(client)(ctrl)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	ctrl.leases.start(stopCh)
+
+	for _, namespace := range []string{"populators", "unused"} {
+		if _, err := ctrl.leases.get(namespace, "held"); err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf(`expected nil error, got "%v"`, err)
+		}
+	}
+	stop := ctrl.leases.stops["unused"]
+	ctrl.pruneLeaseWatches()
+
+	if _, ok := ctrl.leases.listers["populators"]; !ok {
+		t.Errorf("expected the leases of namespace populators to be watched")
+	}
+	if _, ok := ctrl.leases.listers["unused"]; ok {
+		t.Errorf("expected the leases of namespace unused not to be watched")
+	}
+	select {
+	case <-stop:
+	default:
+		t.Errorf("expected the informer of namespace unused to be stopped")
+	}
+}
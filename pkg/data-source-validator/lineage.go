@@ -0,0 +1,296 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamiclister"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// SnapshotResource is the resource of VolumeSnapshots.
+var SnapshotResource = volumesnapshotv1.SchemeGroupVersion.WithResource("volumesnapshots")
+
+// Types of lineage edges.
+const (
+	// edgeClone goes from a PVC to the PVC it was cloned from.
+	edgeClone = "clone"
+	// edgeRestore goes from a PVC to the VolumeSnapshot it was restored
+	// from.
+	edgeRestore = "restore"
+	// edgePopulate goes from a PVC to the populator source it was
+	// populated from.
+	edgePopulate = "populate"
+	// edgeSnapshot goes from a VolumeSnapshot to the PVC it was taken of.
+	edgeSnapshot = "snapshot"
+)
+
+type lineageNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type lineageEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// lineageGraph is the graph of PVCs and the data sources they were created
+// from.
+type lineageGraph struct {
+	Nodes []lineageNode `json:"nodes"`
+	Edges []lineageEdge `json:"edges"`
+}
+
+// WithSnapshotLineage makes the controller follow VolumeSnapshots to the
+// PVCs they were taken of, both in the lineage graph and when measuring
+// clone chains. snapshotInformer must not be started yet.
+func WithSnapshotLineage(snapshotInformer cache.SharedIndexInformer) Option {
+	return func(ctrl *populatorController) {
+		ctrl.snapshotLister = dynamiclister.New(snapshotInformer.GetIndexer(), SnapshotResource)
+		ctrl.snapshotListerSynced = snapshotInformer.HasSynced
+	}
+}
+
+// WithMaxCloneDepth makes the controller emit a CloneChainTooDeep event on
+// Pending PVCs that are more than depth clones away from the original
+// volume.
+func WithMaxCloneDepth(depth int) Option {
+	return func(ctrl *populatorController) {
+		ctrl.maxCloneDepth = depth
+	}
+}
+
+func nodeID(gk metav1.GroupKind, namespace, name string) string {
+	return gk.String() + "/" + namespace + "/" + name
+}
+
+// sourceOf returns the GroupKind, namespace and name of the data source of
+// a PVC. ok is false if the PVC has no data source.
+func sourceOf(pvc *v1.PersistentVolumeClaim) (gk metav1.GroupKind, namespace, name string, ok bool) {
+	dataSourceRef := pvc.Spec.DataSourceRef
+	if dataSourceRef == nil {
+		return metav1.GroupKind{}, "", "", false
+	}
+	namespace = pvc.Namespace
+	if dataSourceRef.Namespace != nil && *dataSourceRef.Namespace != "" {
+		namespace = *dataSourceRef.Namespace
+	}
+	return dataSourceGroupKind(pvc), namespace, dataSourceRef.Name, true
+}
+
+// snapshotSource returns the name of the PVC a VolumeSnapshot was taken of,
+// or "" if it is not known.
+func (ctrl *populatorController) snapshotSource(namespace, name string) (string, error) {
+	if ctrl.snapshotLister == nil {
+		return "", nil
+	}
+	unstSnapshot, err := ctrl.snapshotLister.Namespace(namespace).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var snapshot volumesnapshotv1.VolumeSnapshot
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstSnapshot.UnstructuredContent(), &snapshot); err != nil {
+		return "", err
+	}
+	if snapshot.Spec.Source.PersistentVolumeClaimName == nil {
+		return "", nil
+	}
+	return *snapshot.Spec.Source.PersistentVolumeClaimName, nil
+}
+
+// buildLineage builds the lineage graph of all PVCs.
+func (ctrl *populatorController) buildLineage() (*lineageGraph, error) {
+	pvcs, err := ctrl.pvcLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &lineageGraph{Nodes: []lineageNode{}, Edges: []lineageEdge{}}
+	nodes := make(map[string]bool)
+	addNode := func(gk metav1.GroupKind, namespace, name string) string {
+		id := nodeID(gk, namespace, name)
+		if !nodes[id] {
+			nodes[id] = true
+			graph.Nodes = append(graph.Nodes, lineageNode{ID: id, Kind: gk.String(), Namespace: namespace, Name: name})
+		}
+		return id
+	}
+	snapshots := make(map[string]bool)
+
+	for _, pvc := range pvcs {
+		from := addNode(pvcGK, pvc.Namespace, pvc.Name)
+		gk, namespace, name, ok := sourceOf(pvc)
+		if !ok {
+			continue
+		}
+		to := addNode(gk, namespace, name)
+		edgeType := edgePopulate
+		switch gk {
+		case pvcGK:
+			edgeType = edgeClone
+		case volumeSnapshotGK:
+			edgeType = edgeRestore
+			if !snapshots[to] {
+				snapshots[to] = true
+				source, err := ctrl.snapshotSource(namespace, name)
+				if err != nil {
+					return nil, err
+				}
+				if source != "" {
+					graph.Edges = append(graph.Edges, lineageEdge{From: to, To: addNode(pvcGK, namespace, source), Type: edgeSnapshot})
+				}
+			}
+		}
+		graph.Edges = append(graph.Edges, lineageEdge{From: from, To: to, Type: edgeType})
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph, nil
+}
+
+// dot renders the graph in the Graphviz DOT language.
+func (g *lineageGraph) dot() string {
+	var b strings.Builder
+	b.WriteString("digraph lineage {\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "  %q [label=%q];\n", node.ID, node.Kind+"\n"+node.Namespace+"/"+node.Name)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Type)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// LineageHandler serves the lineage graph of all PVCs as JSON, or as
+// Graphviz DOT with the query parameter format=dot. It responds with 503
+// Service Unavailable until the informers have synced.
+//
+// The graph names the PVCs and data sources of all namespaces and the
+// handler does not authenticate requests, so it only serves clients on the
+// loopback interface, such as kubectl port-forward, and responds with 403
+// Forbidden to all others.
+func (ctrl *populatorController) LineageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopback(r.RemoteAddr) {
+			http.Error(w, "the lineage graph is only served to local clients", http.StatusForbidden)
+			return
+		}
+		if !ctrl.pvcListerSynced() {
+			// Without --webhook-address, replicas only run the informers
+			// while they are the leader.
+			http.Error(w, "pvc informer has not synced", http.StatusServiceUnavailable)
+			return
+		}
+		graph, err := ctrl.buildLineage()
+		if err != nil {
+			klog.Errorf("Failed to build lineage graph: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		switch format := r.URL.Query().Get("format"); format {
+		case "", "json":
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(graph); err != nil {
+				klog.Errorf("Failed to write lineage graph: %v", err)
+			}
+		case "dot":
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+			if _, err := w.Write([]byte(graph.dot())); err != nil {
+				klog.Errorf("Failed to write lineage graph: %v", err)
+			}
+		default:
+			http.Error(w, fmt.Sprintf("unknown format %q, expected json or dot", format), http.StatusBadRequest)
+		}
+	})
+}
+
+// isLoopback returns whether the remote address of a request is on the
+// loopback interface.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// cloneDepth returns how many PVCs a PVC was cloned from, directly or
+// through VolumeSnapshots, before reaching a volume without such a source.
+func (ctrl *populatorController) cloneDepth(pvc *v1.PersistentVolumeClaim) (int, error) {
+	depth := 0
+	seen := map[string]bool{pvc.Namespace + "/" + pvc.Name: true}
+	for {
+		gk, namespace, name, ok := sourceOf(pvc)
+		if !ok {
+			return depth, nil
+		}
+		switch gk {
+		case pvcGK:
+		case volumeSnapshotGK:
+			source, err := ctrl.snapshotSource(namespace, name)
+			if err != nil || source == "" {
+				return depth, err
+			}
+			name = source
+		default:
+			return depth, nil
+		}
+		depth++
+
+		key := namespace + "/" + name
+		if seen[key] {
+			return depth, nil
+		}
+		seen[key] = true
+		source, err := ctrl.pvcLister.PersistentVolumeClaims(namespace).Get(name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return depth, nil
+			}
+			return depth, err
+		}
+		pvc = source
+	}
+}
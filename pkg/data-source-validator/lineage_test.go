@@ -0,0 +1,162 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+func makeChildPVC(name string, group, kind, source string) *v1.PersistentVolumeClaim {
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Phase: v1.ClaimPending,
+		},
+	}
+	if kind != "" {
+		pvc.Spec.DataSourceRef = &v1.TypedObjectReference{APIGroup: ptr.To(group), Kind: kind, Name: source}
+	}
+	return pvc
+}
+
+func makeLineageController(t *testing.T, pvcs ...*v1.PersistentVolumeClaim) *populatorController {
+	scheme := runtime.NewScheme()
+	volumesnapshotv1.AddToScheme(scheme)
+	snapshot := &volumesnapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap", Namespace: "default"},
+		Spec: volumesnapshotv1.VolumeSnapshotSpec{
+			Source: volumesnapshotv1.VolumeSnapshotSource{PersistentVolumeClaimName: ptr.To("clone")},
+		},
+	}
+	dynFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicfake.NewSimpleDynamicClient(scheme, snapshot), 0)
+	snapshotInformer := dynFactory.ForResource(SnapshotResource).Informer()
+
+	ctrl := makeTestController(t, pvcs)
+	WithSnapshotLineage(snapshotInformer)(ctrl)
+
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	dynFactory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, ctrl.snapshotListerSynced)
+	return ctrl
+}
+
+func TestCloneDepth(t *testing.T) {
+	pvcs := map[string]*v1.PersistentVolumeClaim{
+		"original":  makeChildPVC("original", "", "", ""),
+		"clone":     makeChildPVC("clone", "", "PersistentVolumeClaim", "original"),
+		"restored":  makeChildPVC("restored", "snapshot.storage.k8s.io", "VolumeSnapshot", "snap"),
+		"populated": makeChildPVC("populated", "valid.storage.k8s.io", "Valid", "image"),
+		"loop":      makeChildPVC("loop", "", "PersistentVolumeClaim", "loop"),
+	}
+	var objects []*v1.PersistentVolumeClaim
+	for _, pvc := range pvcs {
+		objects = append(objects, pvc)
+	}
+	ctrl := makeLineageController(t, objects...)
+
+	expected := map[string]int{
+		"original":  0,
+		"clone":     1,
+		"restored":  2,
+		"populated": 0,
+		"loop":      1,
+	}
+	for name, depth := range expected {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ctrl.cloneDepth(pvcs[name])
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if actual != depth {
+				t.Errorf(`expected "%v" to equal "%v"`, actual, depth)
+			}
+		})
+	}
+}
+
+func TestLineageHandler(t *testing.T) {
+	ctrl := makeLineageController(t,
+		makeChildPVC("original", "", "", ""),
+		makeChildPVC("clone", "", "PersistentVolumeClaim", "original"),
+		makeChildPVC("restored", "snapshot.storage.k8s.io", "VolumeSnapshot", "snap"),
+	)
+	handler := ctrl.LineageHandler()
+	request := func(target string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.RemoteAddr = "127.0.0.1:40000"
+		return r
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request("/lineage"))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf(`expected "%v" to equal "%v"`, recorder.Code, http.StatusOK)
+	}
+	var graph lineageGraph
+	if err := json.Unmarshal(recorder.Body.Bytes(), &graph); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	expectedEdges := []lineageEdge{
+		{From: "PersistentVolumeClaim/default/clone", To: "PersistentVolumeClaim/default/original", Type: edgeClone},
+		{From: "PersistentVolumeClaim/default/restored", To: "VolumeSnapshot.snapshot.storage.k8s.io/default/snap", Type: edgeRestore},
+		{From: "VolumeSnapshot.snapshot.storage.k8s.io/default/snap", To: "PersistentVolumeClaim/default/clone", Type: edgeSnapshot},
+	}
+	if !reflect.DeepEqual(graph.Edges, expectedEdges) {
+		t.Errorf(`expected "%v" to equal "%v"`, graph.Edges, expectedEdges)
+	}
+	if len(graph.Nodes) != 4 {
+		t.Errorf(`expected 4 nodes, got "%v"`, graph.Nodes)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request("/lineage?format=dot"))
+	expectedEdge := `"PersistentVolumeClaim/default/clone" -> "PersistentVolumeClaim/default/original" [label="clone"];`
+	if body := recorder.Body.String(); !strings.HasPrefix(body, "digraph lineage {") || !strings.Contains(body, expectedEdge) {
+		t.Errorf(`unexpected DOT output "%s"`, body)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request("/lineage?format=svg"))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf(`expected "%v" to equal "%v"`, recorder.Code, http.StatusBadRequest)
+	}
+
+	// Remote clients are not served.
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/lineage", nil))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf(`expected "%v" to equal "%v"`, recorder.Code, http.StatusForbidden)
+	}
+}
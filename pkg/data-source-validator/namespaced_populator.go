@@ -0,0 +1,54 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamiclister"
+	"k8s.io/client-go/tools/cache"
+)
+
+// WithNamespacedPopulators makes the controller accept data sources whose
+// kind is registered by a NamespacedVolumePopulator in the namespace of the
+// PVC. The informer must not be started yet.
+func WithNamespacedPopulators(namespacedPopulatorInformer cache.SharedIndexInformer) Option {
+	return func(ctrl *populatorController) {
+		// A new registration makes the Pending PVCs using its kind
+		// valid, and removing it makes them invalid again, unless another
+		// registration of the kind remains.
+		onChange := func(obj interface{}) {
+			if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+				obj = unknown.Obj
+			}
+			if populator, ok := obj.(*unstructured.Unstructured); ok {
+				group, _, _ := unstructured.NestedString(populator.Object, "sourceKind", "group")
+				kind, _, _ := unstructured.NestedString(populator.Object, "sourceKind", "kind")
+				ctrl.enqueuePendingPVCs(metav1.GroupKind{Group: group, Kind: kind})
+			}
+		}
+		namespacedPopulatorInformer.AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    onChange,
+				UpdateFunc: func(oldObj, newObj interface{}) { onChange(newObj) },
+				DeleteFunc: onChange,
+			},
+		)
+		ctrl.nsPopLister = dynamiclister.New(namespacedPopulatorInformer.GetIndexer(), NamespacedPopulatorResource)
+		ctrl.nsPopListerSynced = namespacedPopulatorInformer.HasSynced
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+func TestValidateNamespacedPopulator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	popv1beta1.AddToScheme(scheme)
+	client := fake.NewSimpleDynamicClient(scheme, &popv1beta1.NamespacedVolumePopulator{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "image-populator"},
+		SourceKind: metav1.GroupKind{Group: "images.example.com", Kind: "Image"},
+	})
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	informer := factory.ForResource(NamespacedPopulatorResource).Informer()
+
+	pending := makeChildPVC("pending", "images.example.com", "Image", "image")
+	pending.Namespace = "tenant"
+	coreFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(pending), 0)
+	pvcInformer := coreFactory.Core().V1().PersistentVolumeClaims()
+	pvcInformer.Informer()
+
+	ctrl := &populatorController{
+		metrics:   new(FakeMetricsManager),
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "pvc"),
+		popLister: makeFakeLister(),
+		pvcLister: pvcInformer.Lister(),
+	}
+	WithNamespacedPopulators(informer)(ctrl)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	coreFactory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, pvcInformer.Informer().HasSynced)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, ctrl.nsPopListerSynced)
+
+	// The new registration makes the Pending PVC valid.
+	err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return ctrl.queue.Len() == 1, nil
+	})
+	if err != nil {
+		t.Errorf("expected the Pending PVC to be enqueued: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		namespace string
+		valid     bool
+		source    validation.SourceType
+	}{
+		{
+			name:      "PVC in the namespace of the registration",
+			namespace: "tenant",
+			valid:     true,
+			source:    validation.SourceNamespacedPopulator,
+		},
+		{
+			name:      "PVC in another namespace",
+			namespace: "default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ctrl.validateSpec(tc.namespace, dataSourceSpec(metav1.GroupKind{Group: "images.example.com", Kind: "Image"}))
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
+			}
+			if result.Source != tc.source {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Source, tc.source)
+			}
+		})
+	}
+
+	// Deleting the registration makes the Pending PVC invalid again.
+	key, _ := ctrl.queue.Get()
+	ctrl.queue.Forget(key)
+	ctrl.queue.Done(key)
+	if err := client.Resource(NamespacedPopulatorResource).Namespace("tenant").Delete(context.TODO(), "image-populator", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	err = wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return ctrl.queue.Len() == 1, nil
+	})
+	if err != nil {
+		t.Errorf("expected the Pending PVC to be enqueued: %v", err)
+	}
+}
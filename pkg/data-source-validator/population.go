@@ -0,0 +1,113 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// population is a PVC with a populator data source that was seen Pending.
+type population struct {
+	uid        types.UID
+	sourceKind metav1.GroupKind
+	stalled    bool
+}
+
+// populationTracker remembers PVCs that wait for a populator, keyed by
+// namespace/name. Only PVCs seen Pending by this process are tracked, so
+// that claims bound before a restart are not counted.
+type populationTracker struct {
+	lock        sync.Mutex
+	populations map[string]*population
+}
+
+func newPopulationTracker() *populationTracker {
+	return &populationTracker{
+		populations: make(map[string]*population),
+	}
+}
+
+// WithPopulationStallThreshold makes the controller emit a PopulationStalled
+// event on PVCs that are still Pending this long after their creation.
+func WithPopulationStallThreshold(threshold time.Duration) Option {
+	return func(ctrl *populatorController) {
+		ctrl.stallThreshold = threshold
+	}
+}
+
+// trackPopulation follows a PVC that passed validation with a populator
+// data source until it is bound.
+func (ctrl *populatorController) trackPopulation(key string, pvc *v1.PersistentVolumeClaim, gk metav1.GroupKind, populator string) {
+	ctrl.populations.lock.Lock()
+	defer ctrl.populations.lock.Unlock()
+
+	p := ctrl.populations.populations[key]
+	if p != nil && p.uid != pvc.UID {
+		// The PVC was deleted and re-created under the same name.
+		p = nil
+	}
+
+	switch pvc.Status.Phase {
+	case v1.ClaimPending:
+		if p == nil {
+			p = &population{uid: pvc.UID, sourceKind: gk}
+			ctrl.populations.populations[key] = p
+		}
+		if ctrl.stallThreshold <= 0 || p.stalled {
+			return
+		}
+		age := time.Since(pvc.CreationTimestamp.Time)
+		if age < ctrl.stallThreshold {
+			// Check again once the threshold has passed.
+			ctrl.queue.AddAfter(key, ctrl.stallThreshold-age)
+			return
+		}
+		p.stalled = true
+		klog.V(2).Infof("PVC %q has been waiting for populator %q for %v", key, populator, age.Round(time.Second))
+		ctrl.pvcWarning(pvc, "PopulationStalled",
+			fmt.Sprintf("The PVC has been waiting for VolumePopulator %s to populate it from %s for %v",
+				populator, gk.String(), age.Round(time.Second)))
+
+	case v1.ClaimBound:
+		if p != nil {
+			duration := time.Since(pvc.CreationTimestamp.Time)
+			klog.V(4).Infof("PVC %q populated from %s in %v", key, gk.String(), duration)
+			ctrl.metrics.RecordPopulationDuration(gk.String(), duration)
+		}
+		delete(ctrl.populations.populations, key)
+
+	default:
+		delete(ctrl.populations.populations, key)
+	}
+}
+
+// forgetPopulation stops tracking a PVC. It returns the tracked population,
+// or nil if the PVC was not tracked.
+func (ctrl *populatorController) forgetPopulation(key string) *population {
+	ctrl.populations.lock.Lock()
+	defer ctrl.populations.lock.Unlock()
+	p := ctrl.populations.populations[key]
+	delete(ctrl.populations.populations, key)
+	return p
+}
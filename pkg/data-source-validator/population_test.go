@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func makePVC(name string, age time.Duration, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID("uid-" + name),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func TestTrackPopulation(t *testing.T) {
+	gk := metav1.GroupKind{Group: "valid.storage.k8s.io", Kind: "Valid"}
+	fakeMetrics := new(FakeMetricsManager)
+	recorder := record.NewFakeRecorder(10)
+	ctrl := &populatorController{
+		eventRecorder:  recorder,
+		metrics:        fakeMetrics,
+		populations:    newPopulationTracker(),
+		stallThreshold: time.Hour,
+		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "pvc"),
+	}
+	defer ctrl.queue.ShutDown()
+
+	testCases := []struct {
+		name   string
+		pvc    *v1.PersistentVolumeClaim
+		event  string
+		record []string
+	}{
+		{
+			name: "Bound before tracking is not recorded",
+			pvc:  makePVC("restarted", 3*time.Hour, v1.ClaimBound),
+		},
+		{
+			name: "Pending below threshold",
+			pvc:  makePVC("young", time.Minute, v1.ClaimPending),
+		},
+		{
+			name:  "Pending above threshold",
+			pvc:   makePVC("old", 2*time.Hour, v1.ClaimPending),
+			event: "Warning PopulationStalled The PVC has been waiting for VolumePopulator valid",
+		},
+		{
+			name: "Stalled event is emitted once",
+			pvc:  makePVC("old", 2*time.Hour, v1.ClaimPending),
+		},
+		{
+			name:   "Bound after population",
+			pvc:    makePVC("old", 3*time.Hour, v1.ClaimBound),
+			record: []string{gk.String()},
+		},
+		{
+			name:   "Bound again is not recorded twice",
+			pvc:    makePVC("old", 3*time.Hour, v1.ClaimBound),
+			record: []string{gk.String()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl.trackPopulation("default/"+tc.pvc.Name, tc.pvc, gk, "valid")
+			select {
+			case event := <-recorder.Events:
+				if tc.event == "" {
+					t.Errorf(`unexpected event "%s"`, event)
+				} else if !strings.HasPrefix(event, tc.event) {
+					t.Errorf(`expected "%s" to equal "%s"`, event, tc.event)
+				}
+			default:
+				if tc.event != "" {
+					t.Errorf(`expected event "%s"`, tc.event)
+				}
+			}
+			if !reflect.DeepEqual(fakeMetrics.populationDurations, tc.record) {
+				t.Errorf(`expected "%v" to equal "%v"`, fakeMetrics.populationDurations, tc.record)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ValidatePopulatorChange checks the creation, update or deletion of a
+// VolumePopulator against the other registrations and the PVCs known to the
+// informers. oldPopulator is nil on creation and newPopulator is nil on
+// deletion. It returns the problems that should reject the change and
+// warnings about it.
+func (ctrl *populatorController) ValidatePopulatorChange(oldPopulator, newPopulator *popv1beta1.VolumePopulator) ([]string, []string, error) {
+	populators, err := ctrl.listPopulators()
+	if err != nil {
+		return nil, nil, err
+	}
+	var problems, warnings []string
+
+	if newPopulator != nil && (oldPopulator == nil || oldPopulator.SourceKind != newPopulator.SourceKind) {
+		for _, other := range populators {
+			if other.Name != newPopulator.Name && other.SourceKind == newPopulator.SourceKind && other.DeletionTimestamp == nil {
+				problems = append(problems, fmt.Sprintf("source kind %s is already registered by VolumePopulator %s", newPopulator.SourceKind.String(), other.Name))
+			}
+		}
+		if ctrl.crdIndexer != nil {
+			served, _, message, err := ctrl.sourceKindServed(newPopulator)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !served {
+				warnings = append(warnings, message)
+			}
+		}
+	}
+
+	if oldPopulator != nil && (newPopulator == nil || newPopulator.SourceKind != oldPopulator.SourceKind ||
+		!sets.New(oldPopulator.ExcludedKinds...).IsSuperset(sets.New(newPopulator.ExcludedKinds...))) {
+		blocking, err := ctrl.blockingPVCs(oldPopulator, newPopulator, populators)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(blocking) > 0 {
+			populator := oldPopulator
+			if newPopulator != nil {
+				populator = newPopulator
+			}
+			message := fmt.Sprintf("Pending PVCs use source kind %s: %s", oldPopulator.SourceKind.String(), listPVCs(blocking))
+			if populator.Annotations[ForceDeleteAnnotation] == "true" {
+				warnings = append(warnings, message)
+			} else {
+				problems = append(problems, fmt.Sprintf("%s. Set annotation %s=true to continue anyway", message, ForceDeleteAnnotation))
+			}
+		}
+	}
+	return problems, warnings, nil
+}
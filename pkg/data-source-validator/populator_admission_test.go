@@ -0,0 +1,181 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+)
+
+func TestValidatePopulatorChange(t *testing.T) {
+	existing := makePopulator("existing", "valid.storage.k8s.io", "Valid")
+	other := makePopulator("other", "other.storage.k8s.io", "Other")
+	client, lister := makeFakeClient(existing)
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(makeSourcePVC("pending", "source", v1.ClaimPending)), 0)
+	pvcInformer := factory.Core().V1().PersistentVolumeClaims()
+	pvcInformer.Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, pvcInformer.Informer().HasSynced)
+	// No CRDs, so no source kind is served.
+	ctrl := &populatorController{
+		dynClient:  client,
+		popLister:  lister,
+		pvcLister:  pvcInformer.Lister(),
+		crdIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{crdGroupKindIndex: crdGroupKindIndexFunc}),
+		discovery:  memory.NewMemCacheClient(fake.NewSimpleClientset().Discovery()),
+	}
+
+	forced := existing.DeepCopy()
+	forced.Annotations = map[string]string{ForceDeleteAnnotation: "true"}
+	moved := existing.DeepCopy()
+	moved.SourceKind = other.SourceKind
+	relabeled := existing.DeepCopy()
+	relabeled.Labels = map[string]string{"app": "populator"}
+
+	testCases := []struct {
+		name         string
+		oldPopulator *popv1beta1.VolumePopulator
+		newPopulator *popv1beta1.VolumePopulator
+		problems     []string
+		warnings     []string
+	}{
+		{
+			name:         "Create new kind",
+			newPopulator: other,
+			warnings:     []string{"no CustomResourceDefinition or API group serves kind Other.other.storage.k8s.io"},
+		},
+		{
+			name:         "Create registered kind",
+			newPopulator: makePopulator("duplicate", "valid.storage.k8s.io", "Valid"),
+			problems:     []string{"source kind Valid.valid.storage.k8s.io is already registered by VolumePopulator existing"},
+			warnings:     []string{"no CustomResourceDefinition or API group serves kind Valid.valid.storage.k8s.io"},
+		},
+		{
+			name:         "Update without kind change",
+			oldPopulator: existing,
+			newPopulator: relabeled,
+		},
+		{
+			name:         "Update strands Pending PVCs",
+			oldPopulator: existing,
+			newPopulator: moved,
+			warnings:     []string{"no CustomResourceDefinition or API group serves kind Other.other.storage.k8s.io"},
+			problems:     []string{"Pending PVCs use source kind Valid.valid.storage.k8s.io: default/pending. Set annotation " + ForceDeleteAnnotation + "=true to continue anyway"},
+		},
+		{
+			name:         "Delete strands Pending PVCs",
+			oldPopulator: existing,
+			problems:     []string{"Pending PVCs use source kind Valid.valid.storage.k8s.io: default/pending. Set annotation " + ForceDeleteAnnotation + "=true to continue anyway"},
+		},
+		{
+			name:         "Forced delete",
+			oldPopulator: forced,
+			warnings:     []string{"Pending PVCs use source kind Valid.valid.storage.k8s.io: default/pending"},
+		},
+		{
+			name:         "Delete unused",
+			oldPopulator: other,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems, warnings, err := ctrl.ValidatePopulatorChange(tc.oldPopulator, tc.newPopulator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if !reflect.DeepEqual(problems, tc.problems) {
+				t.Errorf(`expected "%v" to equal "%v"`, problems, tc.problems)
+			}
+			if !reflect.DeepEqual(warnings, tc.warnings) {
+				t.Errorf(`expected "%v" to equal "%v"`, warnings, tc.warnings)
+			}
+		})
+	}
+}
+
+func TestValidateWildcardPopulatorChange(t *testing.T) {
+	wildcard := makePopulator("vendor", "valid.storage.k8s.io", popv1beta1.WildcardKind)
+	client, lister := makeFakeClient(wildcard)
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(makeSourcePVC("pending", "source", v1.ClaimPending)), 0)
+	pvcInformer := factory.Core().V1().PersistentVolumeClaims()
+	pvcInformer.Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, pvcInformer.Informer().HasSynced)
+	ctrl := &populatorController{
+		dynClient: client,
+		popLister: lister,
+		pvcLister: pvcInformer.Lister(),
+	}
+
+	excludingUsed := wildcard.DeepCopy()
+	excludingUsed.ExcludedKinds = []string{"Valid"}
+	excludingOther := wildcard.DeepCopy()
+	excludingOther.ExcludedKinds = []string{"Other"}
+
+	testCases := []struct {
+		name         string
+		oldPopulator *popv1beta1.VolumePopulator
+		newPopulator *popv1beta1.VolumePopulator
+		problems     []string
+	}{
+		{
+			name:         "Exact kind does not conflict with the wildcard",
+			newPopulator: makePopulator("exact", "valid.storage.k8s.io", "Valid"),
+		},
+		{
+			name:         "Excluding a used kind strands Pending PVCs",
+			oldPopulator: wildcard,
+			newPopulator: excludingUsed,
+			problems:     []string{"Pending PVCs use source kind *.valid.storage.k8s.io: default/pending. Set annotation " + ForceDeleteAnnotation + "=true to continue anyway"},
+		},
+		{
+			name:         "Excluding an unused kind",
+			oldPopulator: wildcard,
+			newPopulator: excludingOther,
+		},
+		{
+			name:         "Delete strands Pending PVCs",
+			oldPopulator: wildcard,
+			problems:     []string{"Pending PVCs use source kind *.valid.storage.k8s.io: default/pending. Set annotation " + ForceDeleteAnnotation + "=true to continue anyway"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			problems, _, err := ctrl.ValidatePopulatorChange(tc.oldPopulator, tc.newPopulator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if !reflect.DeepEqual(problems, tc.problems) {
+				t.Errorf(`expected "%v" to equal "%v"`, problems, tc.problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+const (
+	// PopulatorProtectionFinalizer keeps a VolumePopulator that is being
+	// deleted in place while Pending PVCs use its source kind.
+	// It is removed when the controller starts without populator
+	// protection; see the README for how to remove it otherwise.
+	PopulatorProtectionFinalizer = "datasource-validator.storage.k8s.io/populator-in-use"
+
+	// ForceDeleteAnnotation set to "true" on a VolumePopulator lets it be
+	// deleted even though Pending PVCs use its source kind.
+	ForceDeleteAnnotation = "datasource-validator.storage.k8s.io/force-delete"
+)
+
+// maxListedPVCs limits how many blocking PVCs are named in an event.
+const maxListedPVCs = 5
+
+// WithPopulatorProtection makes the controller add
+// PopulatorProtectionFinalizer to every VolumePopulator and remove it on
+// deletion only once no Pending PVC uses the source kind of the populator,
+// or when ForceDeleteAnnotation is set. Without it, the finalizer is removed
+// from the VolumePopulators that still carry it, which are all synced when
+// the controller starts.
+func WithPopulatorProtection() Option {
+	return func(ctrl *populatorController) {
+		ctrl.populatorProtection = true
+	}
+}
+
+// protectPopulator manages the finalizer of a populator. It returns true
+// when the finalizer was removed, after which the populator is gone.
+func (ctrl *populatorController) protectPopulator(unstPopulator *unstructured.Unstructured, populator *popv1beta1.VolumePopulator, populators []*popv1beta1.VolumePopulator) (bool, error) {
+	has := hasFinalizer(populator.Finalizers, PopulatorProtectionFinalizer)
+	if populator.DeletionTimestamp == nil {
+		if has {
+			return false, nil
+		}
+		return false, ctrl.setPopulatorFinalizers(unstPopulator, append(populator.Finalizers, PopulatorProtectionFinalizer))
+	}
+	if !has {
+		return false, nil
+	}
+
+	blocking, err := ctrl.blockingPVCs(populator, nil, populators)
+	if err != nil {
+		return false, err
+	}
+	if len(blocking) > 0 {
+		if populator.Annotations[ForceDeleteAnnotation] != "true" {
+			klog.V(2).Infof("Deletion of populator %q is blocked by %d PVCs", populator.Name, len(blocking))
+			ctrl.eventRecorder.Eventf(populator, v1.EventTypeWarning, "DeletionBlocked",
+				"Pending PVCs use source kind %s: %s. Set annotation %s=true to delete anyway",
+				populator.SourceKind.String(), listPVCs(blocking), ForceDeleteAnnotation)
+			return false, nil
+		}
+		klog.V(2).Infof("Forcing deletion of populator %q, blocked by %d PVCs", populator.Name, len(blocking))
+		ctrl.eventRecorder.Eventf(populator, v1.EventTypeWarning, "ForcedDeletion",
+			"Deleting although Pending PVCs use source kind %s: %s", populator.SourceKind.String(), listPVCs(blocking))
+	}
+
+	if err := ctrl.releasePopulator(unstPopulator, populator); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// releasePopulator removes PopulatorProtectionFinalizer from a populator.
+func (ctrl *populatorController) releasePopulator(unstPopulator *unstructured.Unstructured, populator *popv1beta1.VolumePopulator) error {
+	var remaining []string
+	for _, finalizer := range populator.Finalizers {
+		if finalizer != PopulatorProtectionFinalizer {
+			remaining = append(remaining, finalizer)
+		}
+	}
+	return ctrl.setPopulatorFinalizers(unstPopulator, remaining)
+}
+
+// blockingPVCs returns the namespace/names of the Pending PVCs that would be
+// stranded by deleting the populator, or by replacing it with an updated
+// one. A PVC is not stranded when the replacement or another populator that
+// is not being deleted registers its source kind, exactly or with a
+// wildcard.
+func (ctrl *populatorController) blockingPVCs(populator, replacement *popv1beta1.VolumePopulator, populators []*popv1beta1.VolumePopulator) ([]string, error) {
+	var others []*popv1beta1.VolumePopulator
+	for _, other := range populators {
+		if other.Name != populator.Name && other.DeletionTimestamp == nil {
+			others = append(others, other)
+		}
+	}
+	if replacement != nil {
+		others = append(others, replacement)
+	}
+	pvcs, err := ctrl.pvcLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var blocking []string
+	for _, pvc := range pvcs {
+		if pvc.Status.Phase != v1.ClaimPending || pvc.DeletionTimestamp != nil {
+			continue
+		}
+		gk := dataSourceGroupKind(pvc)
+		if validation.Matches(populator, gk) && len(validation.MatchPopulators(others, gk)) == 0 {
+			blocking = append(blocking, pvc.Namespace+"/"+pvc.Name)
+		}
+	}
+	sort.Strings(blocking)
+	return blocking, nil
+}
+
+// enqueueDeletingPopulators queues the VolumePopulators registering the
+// given source kind that are being deleted, so that their deletion is
+// checked again.
+func (ctrl *populatorController) enqueueDeletingPopulators(gk metav1.GroupKind) {
+	populators, err := ctrl.listPopulators()
+	if err != nil {
+		klog.Errorf("failed to list populators: %v", err)
+		return
+	}
+	for _, populator := range populators {
+		if populator.DeletionTimestamp != nil && validation.Matches(populator, gk) {
+			ctrl.popQueue.Add(populator.Name)
+		}
+	}
+}
+
+// setPopulatorFinalizers patches the finalizers of a populator, with the
+// resourceVersion of the populator that was read, so that finalizers added
+// concurrently by other controllers are not lost.
+func (ctrl *populatorController) setPopulatorFinalizers(unstPopulator *unstructured.Unstructured, finalizers []string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": unstPopulator.GetResourceVersion(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ctrl.dynClient.Resource(PopulatorResource).Patch(context.TODO(), unstPopulator.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to patch finalizers of populator %q: %w", unstPopulator.GetName(), err)
+	}
+	klog.V(4).Infof("Updated finalizers of populator %q", unstPopulator.GetName())
+	return nil
+}
+
+func hasFinalizer(finalizers []string, finalizer string) bool {
+	for _, f := range finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// listPVCs joins PVC names for an event message, naming at most
+// maxListedPVCs of them.
+func listPVCs(pvcs []string) string {
+	if len(pvcs) <= maxListedPVCs {
+		return strings.Join(pvcs, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(pvcs[:maxListedPVCs], ", "), len(pvcs)-maxListedPVCs)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+func makeDeletingPopulator(name string, annotations map[string]string) *popv1beta1.VolumePopulator {
+	populator := makePopulator(name, "valid.storage.k8s.io", "Valid")
+	now := metav1.Now()
+	populator.DeletionTimestamp = &now
+	populator.Finalizers = []string{PopulatorProtectionFinalizer}
+	populator.Annotations = annotations
+	return populator
+}
+
+func TestProtectPopulator(t *testing.T) {
+	pending := makeSourcePVC("pending", "source", v1.ClaimPending)
+	bound := makeSourcePVC("bound", "source", v1.ClaimBound)
+
+	testCases := []struct {
+		name       string
+		populators []*popv1beta1.VolumePopulator
+		pvcs       []*v1.PersistentVolumeClaim
+		deleted    bool
+		finalizers []string
+		event      string
+	}{
+		{
+			name:       "Finalizer added",
+			populators: []*popv1beta1.VolumePopulator{makePopulator("populator", "valid.storage.k8s.io", "Valid")},
+			finalizers: []string{PopulatorProtectionFinalizer},
+		},
+		{
+			name:       "Deletion blocked by Pending PVC",
+			populators: []*popv1beta1.VolumePopulator{makeDeletingPopulator("populator", nil)},
+			pvcs:       []*v1.PersistentVolumeClaim{pending, bound},
+			finalizers: []string{PopulatorProtectionFinalizer},
+			event:      "Warning DeletionBlocked Pending PVCs use source kind Valid.valid.storage.k8s.io: default/pending.",
+		},
+		{
+			name:       "Deletion with Bound PVCs",
+			populators: []*popv1beta1.VolumePopulator{makeDeletingPopulator("populator", nil)},
+			pvcs:       []*v1.PersistentVolumeClaim{bound},
+			deleted:    true,
+		},
+		{
+			name: "Deletion with another registration",
+			populators: []*popv1beta1.VolumePopulator{
+				makeDeletingPopulator("populator", nil),
+				makePopulator("replacement", "valid.storage.k8s.io", "Valid"),
+			},
+			pvcs:    []*v1.PersistentVolumeClaim{pending},
+			deleted: true,
+		},
+		{
+			name:       "Forced deletion",
+			populators: []*popv1beta1.VolumePopulator{makeDeletingPopulator("populator", map[string]string{ForceDeleteAnnotation: "true"})},
+			pvcs:       []*v1.PersistentVolumeClaim{pending},
+			deleted:    true,
+			event:      "Warning ForcedDeletion Deleting although Pending PVCs use source kind Valid.valid.storage.k8s.io: default/pending",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, lister := makeFakeClient(tc.populators...)
+			var objects []runtime.Object
+			for _, pvc := range tc.pvcs {
+				objects = append(objects, pvc)
+			}
+			factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(objects...), 0)
+			pvcInformer := factory.Core().V1().PersistentVolumeClaims()
+			pvcInformer.Informer()
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			factory.Start(stopCh)
+			cache.WaitForCacheSync(stopCh, pvcInformer.Informer().HasSynced)
+
+			recorder := record.NewFakeRecorder(10)
+			ctrl := &populatorController{
+				dynClient:     client,
+				eventRecorder: recorder,
+				popLister:     lister,
+				pvcLister:     pvcInformer.Lister(),
+			}
+			WithPopulatorProtection()(ctrl)
+
+			unstPopulator, err := lister.Get("populator")
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			populator, err := validation.ConvertPopulator(unstPopulator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			deleted, err := ctrl.protectPopulator(unstPopulator, populator, tc.populators)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if deleted != tc.deleted {
+				t.Errorf(`expected "%v" to equal "%v"`, deleted, tc.deleted)
+			}
+
+			updated, err := client.Resource(PopulatorResource).Get(context.TODO(), "populator", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if finalizers := updated.GetFinalizers(); !reflect.DeepEqual(finalizers, tc.finalizers) {
+				t.Errorf(`expected "%v" to equal "%v"`, finalizers, tc.finalizers)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if tc.event == "" {
+					t.Errorf(`unexpected event "%s"`, event)
+				} else if !strings.HasPrefix(event, tc.event) {
+					t.Errorf(`expected "%s" to equal "%s"`, event, tc.event)
+				}
+			default:
+				if tc.event != "" {
+					t.Errorf(`expected event "%s"`, tc.event)
+				}
+			}
+		})
+	}
+}
+
+func TestReleasePopulatorWithoutProtection(t *testing.T) {
+	testCases := []struct {
+		name      string
+		populator *popv1beta1.VolumePopulator
+	}{
+		{
+			name:      "Protected",
+			populator: makePopulator("populator", "valid.storage.k8s.io", "Valid"),
+		},
+		{
+			name:      "Deleting",
+			populator: makeDeletingPopulator("populator", nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.populator.Finalizers = []string{"example.com/other", PopulatorProtectionFinalizer}
+			client, lister := makeFakeClient(tc.populator)
+			ctrl := makeTestController(t, []*v1.PersistentVolumeClaim{makeSourcePVC("pending", "source", v1.ClaimPending)})
+			ctrl.dynClient = client
+			ctrl.popLister = lister
+
+			if err := ctrl.syncPopulatorByKey("populator"); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			updated, err := client.Resource(PopulatorResource).Get(context.TODO(), "populator", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			expected := []string{"example.com/other"}
+			if finalizers := updated.GetFinalizers(); !reflect.DeepEqual(finalizers, expected) {
+				t.Errorf(`expected "%v" to equal "%v"`, finalizers, expected)
+			}
+		})
+	}
+}
+
+func TestListPVCs(t *testing.T) {
+	pvcs := []string{"ns/a", "ns/b", "ns/c", "ns/d", "ns/e", "ns/f", "ns/g"}
+	expected := "ns/a, ns/b, ns/c, ns/d, ns/e and 2 more"
+	if listed := listPVCs(pvcs); listed != expected {
+		t.Errorf(`expected "%v" to equal "%v"`, listed, expected)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// Provenance annotations, written on a PVC with a validated data source and
+// on its PV once the PVC is bound.
+const (
+	// AnnSourceKind is the GroupKind of the data source, in Kind.group
+	// form.
+	AnnSourceKind = "datasource-validator.storage.k8s.io/source-kind"
+	// AnnSourceName is the name of the data source.
+	AnnSourceName = "datasource-validator.storage.k8s.io/source-name"
+	// AnnSourceNamespace is the namespace of the data source.
+	AnnSourceNamespace = "datasource-validator.storage.k8s.io/source-namespace"
+	// AnnPopulator is the VolumePopulator that matched the data source,
+	// for sources that are not PVCs or VolumeSnapshots.
+	AnnPopulator = "datasource-validator.storage.k8s.io/populator"
+	// AnnProvenanceTime is when the validator saw the PVC go from Pending
+	// to Bound, in RFC 3339 format.
+	AnnProvenanceTime = "datasource-validator.storage.k8s.io/provenance-time"
+	// AnnProvenanceBackfilled is set to "true" instead of
+	// AnnProvenanceTime on PVCs that were already bound when the
+	// validator first saw them, for example after a restart.
+	AnnProvenanceBackfilled = "datasource-validator.storage.k8s.io/provenance-backfilled"
+)
+
+// WithProvenance makes the controller annotate bound PVCs with a validated
+// data source, and their PVs, with where the volume came from.
+func WithProvenance() Option {
+	return func(ctrl *populatorController) {
+		ctrl.provenance = true
+		ctrl.pendingClaims = make(map[string]types.UID)
+	}
+}
+
+// provenanceAnnotations returns the provenance annotations of a PVC. now is
+// the zero time when the binding of the PVC was not seen.
+func provenanceAnnotations(pvc *v1.PersistentVolumeClaim, result validation.Result, now time.Time) map[string]string {
+	dataSourceRef := pvc.Spec.DataSourceRef
+	namespace := pvc.Namespace
+	if dataSourceRef.Namespace != nil && *dataSourceRef.Namespace != "" {
+		namespace = *dataSourceRef.Namespace
+	}
+	gk := dataSourceGroupKind(pvc)
+	annotations := map[string]string{
+		AnnSourceKind:      gk.String(),
+		AnnSourceName:      dataSourceRef.Name,
+		AnnSourceNamespace: namespace,
+	}
+	if now.IsZero() {
+		annotations[AnnProvenanceBackfilled] = "true"
+	} else {
+		annotations[AnnProvenanceTime] = now.UTC().Format(time.RFC3339)
+	}
+	if result.Populator != nil {
+		annotations[AnnPopulator] = result.Populator.Name
+	}
+	return annotations
+}
+
+// recordProvenance remembers a Pending PVC, and annotates a bound PVC and
+// its PV. The PV is annotated first, so that an annotated PVC means both
+// are done.
+func (ctrl *populatorController) recordProvenance(key string, pvc *v1.PersistentVolumeClaim, result validation.Result) error {
+	if pvc.Status.Phase == v1.ClaimPending {
+		ctrl.pendingClaimsLock.Lock()
+		ctrl.pendingClaims[key] = pvc.UID
+		ctrl.pendingClaimsLock.Unlock()
+		return nil
+	}
+	if pvc.Status.Phase != v1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return nil
+	}
+	if _, ok := pvc.Annotations[AnnSourceKind]; ok {
+		ctrl.forgetPendingClaim(key)
+		return nil
+	}
+
+	var now time.Time
+	ctrl.pendingClaimsLock.Lock()
+	if uid, ok := ctrl.pendingClaims[key]; ok && uid == pvc.UID {
+		now = time.Now()
+	}
+	ctrl.pendingClaimsLock.Unlock()
+	annotations := provenanceAnnotations(pvc, result, now)
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ctrl.client.CoreV1().PersistentVolumes().Patch(context.TODO(), pvc.Spec.VolumeName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to annotate pv %q: %w", pvc.Spec.VolumeName, err)
+	}
+	_, err = ctrl.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(context.TODO(), pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to annotate pvc %s/%s: %w", pvc.Namespace, pvc.Name, err)
+	}
+	klog.V(4).Infof("Recorded provenance of pvc %s/%s from %s %s", pvc.Namespace, pvc.Name, annotations[AnnSourceKind], annotations[AnnSourceName])
+	ctrl.forgetPendingClaim(key)
+	return nil
+}
+
+// forgetPendingClaim stops remembering a PVC that is annotated or deleted.
+func (ctrl *populatorController) forgetPendingClaim(key string) {
+	if ctrl.pendingClaims == nil {
+		return
+	}
+	ctrl.pendingClaimsLock.Lock()
+	defer ctrl.pendingClaimsLock.Unlock()
+	delete(ctrl.pendingClaims, key)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+func TestRecordProvenance(t *testing.T) {
+	testCases := []struct {
+		name        string
+		seenPending bool
+		uid         types.UID
+		backfilled  bool
+	}{
+		{
+			name:        "Seen Pending",
+			seenPending: true,
+		},
+		{
+			name:       "Bound before it was seen",
+			backfilled: true,
+		},
+		{
+			name:        "Re-created after it was seen Pending",
+			seenPending: true,
+			uid:         "other",
+			backfilled:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pvc := makeSourcePVC("pvc", "golden-image", v1.ClaimBound)
+			pvc.Spec.VolumeName = "pv"
+			pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv"}}
+			client := fake.NewSimpleClientset(pvc, pv)
+			ctrl := &populatorController{client: client}
+			WithProvenance()(ctrl)
+
+			result := validation.Result{Valid: true, Populator: makePopulator("populator", "valid.storage.k8s.io", "Valid")}
+			if tc.seenPending {
+				pending := makeSourcePVC("pvc", "golden-image", v1.ClaimPending)
+				if tc.uid != "" {
+					pending.UID = tc.uid
+				}
+				if err := ctrl.recordProvenance("default/pvc", pending, result); err != nil {
+					t.Fatalf(`expected nil error, got "%v"`, err)
+				}
+				if len(client.Actions()) != 0 {
+					t.Errorf("unexpected actions %v", client.Actions())
+				}
+			}
+			if err := ctrl.recordProvenance("default/pvc", pvc, result); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+
+			expected := map[string]string{
+				AnnSourceKind:      "Valid.valid.storage.k8s.io",
+				AnnSourceName:      "golden-image",
+				AnnSourceNamespace: "default",
+				AnnPopulator:       "populator",
+			}
+			if tc.backfilled {
+				expected[AnnProvenanceBackfilled] = "true"
+			}
+			updatedPV, err := client.CoreV1().PersistentVolumes().Get(context.TODO(), "pv", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			updatedPVC, err := client.CoreV1().PersistentVolumeClaims("default").Get(context.TODO(), "pvc", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			for _, annotations := range []map[string]string{updatedPV.Annotations, updatedPVC.Annotations} {
+				if timestamp := annotations[AnnProvenanceTime]; (timestamp != "") == tc.backfilled {
+					t.Errorf(`unexpected %s "%s"`, AnnProvenanceTime, timestamp)
+				}
+				delete(annotations, AnnProvenanceTime)
+				if !reflect.DeepEqual(annotations, expected) {
+					t.Errorf(`expected "%v" to equal "%v"`, annotations, expected)
+				}
+			}
+			if len(ctrl.pendingClaims) != 0 {
+				t.Errorf("expected the PVC to be forgotten, got %v", ctrl.pendingClaims)
+			}
+
+			// Already annotated PVCs are not patched again.
+			client.ClearActions()
+			if err := ctrl.recordProvenance("default/pvc", updatedPVC, result); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if len(client.Actions()) != 0 {
+				t.Errorf("unexpected actions %v", client.Actions())
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// ValidatePVCCreation checks a PVC that is being created by the given user
+// against the population quota of its namespace and source kind, and, with
+// WithSourceAccessCheck, that the user may get its data source. It returns
+// the problems that should reject the PVC.
+func (ctrl *populatorController) ValidatePVCCreation(pvc *v1.PersistentVolumeClaim, user authenticationv1.UserInfo) ([]string, error) {
+	var problems []string
+	if ctrl.sourceAccessCheck {
+		if problem := ctrl.checkSourceAccess(pvc, user); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	problem, err := ctrl.checkCreationQuota(pvc)
+	if err != nil {
+		if len(problems) > 0 {
+			// Reject the PVC for the problems found so far, as
+			// errors make the webhook admit it.
+			klog.Errorf("Failed to check the population quota of PVC %s/%s: %v", pvc.Namespace, pvc.Name, err)
+			return problems, nil
+		}
+		return nil, err
+	}
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
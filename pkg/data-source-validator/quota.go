@@ -0,0 +1,137 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+	"sort"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// checkPopulationQuota records the usage of the population quota of a PVC's
+// namespace and source kind, and warns about a Pending PVC beyond the quota.
+// The oldest Pending PVCs are within the quota, so that PVCs created during
+// a burst are flagged, not the ones created before it.
+func (ctrl *populatorController) checkPopulationQuota(pvc *v1.PersistentVolumeClaim, gk metav1.GroupKind, populator *popv1beta1.VolumePopulator) error {
+	limit := int(*populator.MaxPendingPVCsPerNamespace)
+	pending, err := ctrl.pendingPopulations(pvc.Namespace, gk)
+	if err != nil {
+		return err
+	}
+	ctrl.metrics.SetPopulationQuotaUsage(pvc.Namespace, gk.String(), len(pending), limit)
+
+	for i, p := range pending {
+		if p.UID != pvc.UID {
+			continue
+		}
+		if i >= limit {
+			klog.V(2).Infof("PVC %s/%s is Pending PVC %d of %s, the quota is %d", pvc.Namespace, pvc.Name, i+1, gk.String(), limit)
+			ctrl.pvcWarning(pvc, "PopulationQuotaExceeded",
+				fmt.Sprintf("%d PVCs in namespace %s are Pending with source kind %s, more than the limit of %d set by %s",
+					len(pending), pvc.Namespace, gk.String(), limit, validation.DescribePopulator(populator)))
+		}
+		break
+	}
+	return nil
+}
+
+// refreshPopulationQuota records the usage of the population quota of a
+// namespace and source kind, after a PVC that used it was deleted.
+func (ctrl *populatorController) refreshPopulationQuota(namespace string, gk metav1.GroupKind) error {
+	populator, err := ctrl.quotaPopulator(gk)
+	if err != nil || populator == nil {
+		return err
+	}
+	pending, err := ctrl.pendingPopulations(namespace, gk)
+	if err != nil {
+		return err
+	}
+	ctrl.metrics.SetPopulationQuotaUsage(namespace, gk.String(), len(pending), int(*populator.MaxPendingPVCsPerNamespace))
+	return nil
+}
+
+// quotaPopulator returns the VolumePopulator that registers a source kind,
+// if it sets a population quota.
+func (ctrl *populatorController) quotaPopulator(gk metav1.GroupKind) (*popv1beta1.VolumePopulator, error) {
+	populators, err := ctrl.listPopulators()
+	if err != nil {
+		return nil, err
+	}
+	matched := validation.MatchPopulators(populators, gk)
+	if len(matched) == 0 || matched[0].MaxPendingPVCsPerNamespace == nil {
+		return nil, nil
+	}
+	return matched[0], nil
+}
+
+// pendingPopulations returns the Pending PVCs of a namespace that use the
+// given source kind, oldest first.
+func (ctrl *populatorController) pendingPopulations(namespace string, gk metav1.GroupKind) ([]*v1.PersistentVolumeClaim, error) {
+	pvcs, err := ctrl.pvcLister.PersistentVolumeClaims(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var pending []*v1.PersistentVolumeClaim
+	for _, pvc := range pvcs {
+		if pvc.Status.Phase == v1.ClaimPending && pvc.DeletionTimestamp == nil && dataSourceGroupKind(pvc) == gk {
+			pending = append(pending, pvc)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].CreationTimestamp.Equal(&pending[j].CreationTimestamp) {
+			return pending[i].CreationTimestamp.Before(&pending[j].CreationTimestamp)
+		}
+		return pending[i].Name < pending[j].Name
+	})
+	return pending, nil
+}
+
+// checkCreationQuota returns the problem that rejects a PVC that is being
+// created beyond the population quota, or an empty string. The PVCs known to
+// the informer are counted, so PVCs created in a quick burst may exceed the
+// quota by the ones the informer has not seen yet; they are flagged by
+// events later.
+func (ctrl *populatorController) checkCreationQuota(pvc *v1.PersistentVolumeClaim) (string, error) {
+	if pvc.Spec.DataSourceRef == nil {
+		return "", nil
+	}
+	gk := dataSourceGroupKind(pvc)
+	if gk == pvcGK || gk == volumeSnapshotGK || ctrl.skipWhileDegraded(gk) {
+		return "", nil
+	}
+	populator, err := ctrl.quotaPopulator(gk)
+	if err != nil || populator == nil {
+		return "", err
+	}
+	limit := int(*populator.MaxPendingPVCsPerNamespace)
+	pending, err := ctrl.pendingPopulations(pvc.Namespace, gk)
+	if err != nil {
+		return "", err
+	}
+	if len(pending) < limit {
+		return "", nil
+	}
+	return fmt.Sprintf("%d PVCs in namespace %s are already Pending with source kind %s, the limit set by %s is %d",
+		len(pending), pvc.Namespace, gk.String(), validation.DescribePopulator(populator), limit), nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+func makeQuotaController(t *testing.T, limit int32, pvcs ...*v1.PersistentVolumeClaim) *populatorController {
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.MaxPendingPVCsPerNamespace = ptr.To(limit)
+	return makeTestController(t, pvcs, populator)
+}
+
+func TestCheckPopulationQuota(t *testing.T) {
+	pvcs := []*v1.PersistentVolumeClaim{
+		makeSourcePVC("first", "source", v1.ClaimPending),
+		makeSourcePVC("second", "source", v1.ClaimPending),
+		makeSourcePVC("third", "source", v1.ClaimPending),
+		makeSourcePVC("bound", "source", v1.ClaimBound),
+	}
+	// The PVCs were created in order, one minute apart.
+	for i, pvc := range pvcs {
+		pvc.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(i-len(pvcs)) * time.Minute))
+	}
+	ctrl := makeQuotaController(t, 2, pvcs...)
+
+	testCases := []struct {
+		name  string
+		event string
+	}{
+		{
+			name: "first",
+		},
+		{
+			name: "second",
+		},
+		{
+			name:  "third",
+			event: "Warning PopulationQuotaExceeded 3 PVCs in namespace default are Pending with source kind Valid.valid.storage.k8s.io, more than the limit of 2 set by VolumePopulator valid",
+		},
+		{
+			name: "bound",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ctrl.syncPvcByKey("default/" + tc.name); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			select {
+			case event := <-ctrl.eventRecorder.(*record.FakeRecorder).Events:
+				if event != tc.event {
+					t.Errorf(`expected "%s" to equal "%s"`, event, tc.event)
+				}
+			default:
+				if tc.event != "" {
+					t.Errorf(`expected event "%s"`, tc.event)
+				}
+			}
+		})
+	}
+
+	expected := map[string]int{"default/Valid.valid.storage.k8s.io": 3}
+	if usage := ctrl.metrics.(*FakeMetricsManager).quotaUsage; !reflect.DeepEqual(usage, expected) {
+		t.Errorf(`expected "%v" to equal "%v"`, usage, expected)
+	}
+
+	// A deleted Pending PVC no longer counts.
+	if err := ctrl.client.CoreV1().PersistentVolumeClaims("default").Delete(context.TODO(), "third", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	err := wait.PollUntilContextTimeout(context.TODO(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		_, err := ctrl.pvcLister.PersistentVolumeClaims("default").Get("third")
+		return errors.IsNotFound(err), nil
+	})
+	if err != nil {
+		t.Fatalf("expected the informer to see the deletion: %v", err)
+	}
+	if err := ctrl.syncPvcByKey("default/third"); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	expected = map[string]int{"default/Valid.valid.storage.k8s.io": 2}
+	if usage := ctrl.metrics.(*FakeMetricsManager).quotaUsage; !reflect.DeepEqual(usage, expected) {
+		t.Errorf(`expected "%v" to equal "%v"`, usage, expected)
+	}
+}
+
+func TestValidatePVCCreation(t *testing.T) {
+	pending := []*v1.PersistentVolumeClaim{
+		makeSourcePVC("first", "source", v1.ClaimPending),
+		makeSourcePVC("second", "source", v1.ClaimPending),
+	}
+
+	testCases := []struct {
+		name     string
+		limit    int32
+		pvc      *v1.PersistentVolumeClaim
+		problems []string
+	}{
+		{
+			name:  "Within quota",
+			limit: 3,
+			pvc:   makeSourcePVC("new", "source", ""),
+		},
+		{
+			name:     "Quota exceeded",
+			limit:    2,
+			pvc:      makeSourcePVC("new", "source", ""),
+			problems: []string{"2 PVCs in namespace default are already Pending with source kind Valid.valid.storage.k8s.io, the limit set by VolumePopulator valid is 2"},
+		},
+		{
+			name:  "Clone",
+			limit: 2,
+			pvc:   makeChildPVC("new", "", "PersistentVolumeClaim", "original"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := makeQuotaController(t, tc.limit, pending...)
+			problems, err := ctrl.ValidatePVCCreation(tc.pvc, authenticationv1.UserInfo{Username: "dev"})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if !reflect.DeepEqual(problems, tc.problems) {
+				t.Errorf(`expected "%v" to equal "%v"`, strings.Join(problems, "; "), strings.Join(tc.problems, "; "))
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// WithSourceAccessCheck makes ValidatePVCCreation reject PVCs whose creator
+// is not allowed to get their data source, as populators and CSI drivers
+// read the source with their own, usually broader, permissions. mapper maps
+// source kinds to their resources.
+func WithSourceAccessCheck(mapper meta.RESTMapper) Option {
+	return func(ctrl *populatorController) {
+		ctrl.sourceAccessCheck = true
+		ctrl.mapper = mapper
+	}
+}
+
+// checkSourceAccess confirms with a SubjectAccessReview that a user may get
+// the data source of a PVC. It returns the problem that rejects the PVC, or
+// an empty string if the user is allowed or the PVC has no data source. The
+// check fails closed: a source that cannot be checked is a problem.
+func (ctrl *populatorController) checkSourceAccess(pvc *v1.PersistentVolumeClaim, user authenticationv1.UserInfo) string {
+	dataSourceRef := pvc.Spec.DataSourceRef
+	if dataSourceRef == nil && pvc.Spec.DataSource != nil {
+		dataSourceRef = &v1.TypedObjectReference{
+			APIGroup: pvc.Spec.DataSource.APIGroup,
+			Kind:     pvc.Spec.DataSource.Kind,
+			Name:     pvc.Spec.DataSource.Name,
+		}
+	}
+	if dataSourceRef == nil {
+		return ""
+	}
+	gk := validation.DataSourceGroupKind(dataSourceRef)
+	namespace := pvc.Namespace
+	if dataSourceRef.Namespace != nil && *dataSourceRef.Namespace != "" {
+		namespace = *dataSourceRef.Namespace
+	}
+	source := namespace + "/" + dataSourceRef.Name
+
+	mapping, err := ctrl.restMapping(gk)
+	if err != nil {
+		klog.V(2).Infof("Cannot map %s of PVC %s/%s: %v", gk.String(), pvc.Namespace, pvc.Name, err)
+		return fmt.Sprintf("Cannot check that user %s can get the data source %s %s: %v", user.Username, gk.String(), source, err)
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		namespace = ""
+		source = dataSourceRef.Name
+	}
+
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "get",
+				Group:     mapping.Resource.Group,
+				Version:   mapping.Resource.Version,
+				Resource:  mapping.Resource.Resource,
+				Name:      dataSourceRef.Name,
+			},
+		},
+	}
+	review, err = ctrl.client.AuthorizationV1().SubjectAccessReviews().Create(context.TODO(), review, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to review access of user %s to %s %s: %v", user.Username, gk.String(), source, err)
+		return fmt.Sprintf("Cannot check that user %s can get the data source %s %s: %v", user.Username, gk.String(), source, err)
+	}
+	if !review.Status.Allowed {
+		klog.V(2).Infof("User %s may not get %s %s, the data source of PVC %s/%s", user.Username, gk.String(), source, pvc.Namespace, pvc.Name)
+		return fmt.Sprintf("User %s is not allowed to get the data source %s %s", user.Username, gk.String(), source)
+	}
+	return ""
+}
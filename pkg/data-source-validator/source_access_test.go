@@ -0,0 +1,154 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+func TestCheckSourceAccess(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{sampleGVR.GroupVersion(), v1.SchemeGroupVersion})
+	mapper.Add(sampleGVR.GroupVersion().WithKind("Valid"), meta.RESTScopeNamespace)
+	mapper.Add(v1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), meta.RESTScopeNamespace)
+	user := authenticationv1.UserInfo{
+		Username: "dev",
+		Groups:   []string{"developers"},
+		Extra:    map[string]authenticationv1.ExtraValue{"scopes": {"storage"}},
+	}
+
+	testCases := []struct {
+		name     string
+		pvc      *v1.PersistentVolumeClaim
+		allowed  bool
+		err      error
+		problems []string
+		// attributes are the attributes of the expected review.
+		attributes *authorizationv1.ResourceAttributes
+	}{
+		{
+			name:       "Allowed",
+			pvc:        makeSourcePVC("claim", "source", ""),
+			allowed:    true,
+			attributes: &authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Group: "valid.storage.k8s.io", Version: "v1", Resource: "valids", Name: "source"},
+		},
+		{
+			name:       "Denied",
+			pvc:        makeSourcePVC("claim", "source", ""),
+			problems:   []string{"User dev is not allowed to get the data source Valid.valid.storage.k8s.io default/source"},
+			attributes: &authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Group: "valid.storage.k8s.io", Version: "v1", Resource: "valids", Name: "source"},
+		},
+		{
+			name: "Cross-namespace source",
+			pvc: func() *v1.PersistentVolumeClaim {
+				pvc := makeChildPVC("claim", "", "PersistentVolumeClaim", "original")
+				pvc.Spec.DataSourceRef.Namespace = ptr.To("golden")
+				return pvc
+			}(),
+			problems:   []string{"User dev is not allowed to get the data source PersistentVolumeClaim golden/original"},
+			attributes: &authorizationv1.ResourceAttributes{Namespace: "golden", Verb: "get", Version: "v1", Resource: "persistentvolumeclaims", Name: "original"},
+		},
+		{
+			name: "dataSource only",
+			pvc: func() *v1.PersistentVolumeClaim {
+				pvc := makePVC("claim", 0, "")
+				pvc.Spec.DataSource = &v1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "original"}
+				return pvc
+			}(),
+			allowed:    true,
+			attributes: &authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Version: "v1", Resource: "persistentvolumeclaims", Name: "original"},
+		},
+		{
+			name:     "Unknown kind",
+			pvc:      makeChildPVC("claim", "unknown.storage.k8s.io", "Unknown", "source"),
+			problems: []string{`Cannot check that user dev can get the data source Unknown.unknown.storage.k8s.io default/source: no matches for kind "Unknown" in group "unknown.storage.k8s.io"`},
+		},
+		{
+			name:       "Review error",
+			pvc:        makeSourcePVC("claim", "source", ""),
+			err:        errors.New("unavailable"),
+			problems:   []string{"Cannot check that user dev can get the data source Valid.valid.storage.k8s.io default/source: unavailable"},
+			attributes: &authorizationv1.ResourceAttributes{Namespace: "default", Verb: "get", Group: "valid.storage.k8s.io", Version: "v1", Resource: "valids", Name: "source"},
+		},
+		{
+			name: "No data source",
+			pvc:  makePVC("claim", 0, ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			var reviews []*authorizationv1.SubjectAccessReview
+			client.PrependReactor("create", "subjectaccessreviews", func(action core.Action) (bool, runtime.Object, error) {
+				review := action.(core.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				reviews = append(reviews, review)
+				if tc.err != nil {
+					return true, nil, tc.err
+				}
+				review = review.DeepCopy()
+				review.Status.Allowed = tc.allowed
+				return true, review, nil
+			})
+			ctrl := &populatorController{
+				client:        client,
+				eventRecorder: record.NewFakeRecorder(10),
+				metrics:       new(FakeMetricsManager),
+				popLister:     makeFakeLister(),
+			}
+			WithSourceAccessCheck(mapper)(ctrl)
+
+			problems, err := ctrl.ValidatePVCCreation(tc.pvc, user)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if !reflect.DeepEqual(problems, tc.problems) {
+				t.Errorf(`expected "%v" to equal "%v"`, strings.Join(problems, "; "), strings.Join(tc.problems, "; "))
+			}
+
+			if tc.attributes == nil {
+				if len(reviews) != 0 {
+					t.Errorf(`expected no review, got "%v"`, reviews)
+				}
+				return
+			}
+			if len(reviews) != 1 {
+				t.Fatalf(`expected "%v" to equal "%v"`, len(reviews), 1)
+			}
+			spec := reviews[0].Spec
+			if spec.User != user.Username || !reflect.DeepEqual(spec.Groups, user.Groups) || !reflect.DeepEqual(spec.Extra["scopes"], authorizationv1.ExtraValue{"storage"}) {
+				t.Errorf(`expected the review of "%v", got "%v"`, user, spec)
+			}
+			if !reflect.DeepEqual(spec.ResourceAttributes, tc.attributes) {
+				t.Errorf(`expected "%v" to equal "%v"`, spec.ResourceAttributes, tc.attributes)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+	"strings"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// crdGroupKindIndex indexes CustomResourceDefinitions by the GroupKind they
+// define.
+const crdGroupKindIndex = "groupKind"
+
+// WithSourceKindCheck makes the controller check that the source kind of
+// every VolumePopulator is served by the API server. Kinds are looked up in
+// the CustomResourceDefinitions of crdInformer first and in discovery
+// otherwise. crdInformer must not be started yet.
+func WithSourceKindCheck(crdInformer cache.SharedIndexInformer, discoveryClient discovery.DiscoveryInterface) Option {
+	return func(ctrl *populatorController) {
+		err := crdInformer.AddIndexers(cache.Indexers{crdGroupKindIndex: crdGroupKindIndexFunc})
+		if err != nil {
+			klog.Fatalf("Failed to add CRD index: %v", err)
+		}
+		ctrl.discovery = memory.NewMemCacheClient(discoveryClient)
+		// A CRD change can start or stop serving the source kind of any
+		// populator.
+		onChange := func() {
+			ctrl.discovery.Invalidate()
+			ctrl.enqueueAllPopulators()
+		}
+		crdInformer.AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { onChange() },
+				UpdateFunc: func(oldObj, newObj interface{}) { onChange() },
+				DeleteFunc: func(obj interface{}) { onChange() },
+			},
+		)
+		ctrl.crdIndexer = crdInformer.GetIndexer()
+		ctrl.crdListerSynced = crdInformer.HasSynced
+	}
+}
+
+func crdGroupKindIndexFunc(obj interface{}) ([]string, error) {
+	crd, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	gk := metav1.GroupKind{Group: group, Kind: kind}
+	return []string{gk.String()}, nil
+}
+
+// sourceKindChecker checks source kinds for the validation library.
+type sourceKindChecker struct {
+	ctrl *populatorController
+}
+
+func (c sourceKindChecker) SourceKindServed(populator *popv1beta1.VolumePopulator) (bool, string, string, error) {
+	return c.ctrl.sourceKindServed(populator)
+}
+
+// sourceKindServed checks that the source kind of the populator is served in
+// all of its required versions. If not, it returns the reason for the
+// SourceKindServed condition and a message explaining why. For wildcard
+// registrations, it checks that the group is served; the kinds are checked
+// when PVCs use them.
+func (ctrl *populatorController) sourceKindServed(populator *popv1beta1.VolumePopulator) (bool, string, string, error) {
+	gk := populator.SourceKind
+	if validation.IsWildcard(populator) {
+		served, err := ctrl.groupServed(gk.Group)
+		if err != nil {
+			return false, "", "", err
+		}
+		if !served {
+			return false, popv1beta1.VolumePopulatorReasonKindNotServed,
+				fmt.Sprintf("no CustomResourceDefinition or API group serves group %s", gk.Group), nil
+		}
+		return true, popv1beta1.VolumePopulatorReasonKindServed, fmt.Sprintf("Group %s is served", gk.Group), nil
+	}
+	served, err := ctrl.servedVersions(gk)
+	if err != nil {
+		return false, "", "", err
+	}
+	if served.Len() == 0 {
+		return false, popv1beta1.VolumePopulatorReasonKindNotServed,
+			fmt.Sprintf("no CustomResourceDefinition or API group serves kind %s", gk.String()), nil
+	}
+	missing := sets.New(populator.RequiredVersions...).Difference(served)
+	if missing.Len() > 0 {
+		return false, popv1beta1.VolumePopulatorReasonVersionsNotServed,
+			fmt.Sprintf("kind %s is served in versions %s, but %s are required",
+				gk.String(), strings.Join(sets.List(served), ", "), strings.Join(sets.List(missing), ", ")), nil
+	}
+	return true, popv1beta1.VolumePopulatorReasonKindServed, fmt.Sprintf("Kind %s is served", gk.String()), nil
+}
+
+// servedVersions returns the versions in which the API server serves the
+// given kind.
+func (ctrl *populatorController) servedVersions(gk metav1.GroupKind) (sets.Set[string], error) {
+	crds, err := ctrl.crdIndexer.ByIndex(crdGroupKindIndex, gk.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(crds) > 0 {
+		versions := sets.New[string]()
+		for _, obj := range crds {
+			crd := obj.(*unstructured.Unstructured)
+			if !crdEstablished(crd) {
+				klog.V(4).Infof("CRD %q for %s is not established", crd.GetName(), gk.String())
+				continue
+			}
+			crdVersions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+			for _, v := range crdVersions {
+				version, ok := v.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if served, _, _ := unstructured.NestedBool(version, "served"); served {
+					name, _, _ := unstructured.NestedString(version, "name")
+					versions.Insert(name)
+				}
+			}
+		}
+		return versions, nil
+	}
+
+	// Not a CRD, the kind may still be served by an aggregated or
+	// built-in API.
+	return ctrl.discoveredVersions(gk)
+}
+
+// groupServed returns whether an established CRD or the API server serves
+// any kind of the given group.
+func (ctrl *populatorController) groupServed(group string) (bool, error) {
+	for _, obj := range ctrl.crdIndexer.List() {
+		crd, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if crdGroup, _, _ := unstructured.NestedString(crd.Object, "spec", "group"); crdGroup == group && crdEstablished(crd) {
+			return true, nil
+		}
+	}
+	groups, err := ctrl.discovery.ServerGroups()
+	if err != nil {
+		return false, err
+	}
+	if groups == nil {
+		return false, nil
+	}
+	for _, g := range groups.Groups {
+		if g.Name == group {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ctrl *populatorController) discoveredVersions(gk metav1.GroupKind) (sets.Set[string], error) {
+	versions := sets.New[string]()
+	groups, err := ctrl.discovery.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	if groups == nil {
+		return versions, nil
+	}
+	for _, group := range groups.Groups {
+		if group.Name != gk.Group {
+			continue
+		}
+		for _, gv := range group.Versions {
+			resources, err := ctrl.discovery.ServerResourcesForGroupVersion(gv.GroupVersion)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			for _, resource := range resources.APIResources {
+				// Skip subresources, they share the kind of their parent.
+				if resource.Kind == gk.Kind && !strings.Contains(resource.Name, "/") {
+					versions.Insert(gv.Version)
+				}
+			}
+		}
+	}
+	return versions, nil
+}
+
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Established" {
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+// sourceKindServedCondition builds the SourceKindServed condition of a
+// populator.
+func (ctrl *populatorController) sourceKindServedCondition(populator *popv1beta1.VolumePopulator) (metav1.Condition, error) {
+	served, reason, message, err := ctrl.sourceKindServed(populator)
+	if err != nil {
+		return metav1.Condition{}, err
+	}
+	status := metav1.ConditionTrue
+	if !served {
+		status = metav1.ConditionFalse
+	}
+	return metav1.Condition{
+		Type:               popv1beta1.VolumePopulatorSourceKindServed,
+		Status:             status,
+		ObservedGeneration: populator.Generation,
+		Reason:             reason,
+		Message:            message,
+	}, nil
+}
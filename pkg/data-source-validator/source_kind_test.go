@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+)
+
+func makeCRD(group, kind string, established bool, versions map[string]bool) *unstructured.Unstructured {
+	crdVersions := []interface{}{}
+	for name, served := range versions {
+		crdVersions = append(crdVersions, map[string]interface{}{
+			"name":   name,
+			"served": served,
+		})
+	}
+	status := "False"
+	if established {
+		status = "True"
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": kind + "." + group,
+			},
+			"spec": map[string]interface{}{
+				"group": group,
+				"names": map[string]interface{}{
+					"kind": kind,
+				},
+				"versions": crdVersions,
+			},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":   "Established",
+						"status": status,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSourceKindServed(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{crdGroupKindIndex: crdGroupKindIndexFunc})
+	indexer.Add(makeCRD("crd.storage.k8s.io", "Served", true, map[string]bool{"v1": true, "v1beta1": false}))
+	indexer.Add(makeCRD("crd.storage.k8s.io", "Pending", false, map[string]bool{"v1": true}))
+
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "aggregated.storage.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "images", Kind: "Image"},
+				{Name: "images/status", Kind: "Image"},
+			},
+		},
+	}
+
+	ctrl := &populatorController{
+		crdIndexer: indexer,
+		discovery:  memory.NewMemCacheClient(client.Discovery()),
+		metrics:    new(FakeMetricsManager),
+	}
+
+	testCases := []struct {
+		name     string
+		gk       metav1.GroupKind
+		versions []string
+		served   bool
+		reason   string
+	}{
+		{
+			name:   "CRD served",
+			gk:     metav1.GroupKind{Group: "crd.storage.k8s.io", Kind: "Served"},
+			served: true,
+			reason: popv1beta1.VolumePopulatorReasonKindServed,
+		},
+		{
+			name:     "CRD serves required version",
+			gk:       metav1.GroupKind{Group: "crd.storage.k8s.io", Kind: "Served"},
+			versions: []string{"v1"},
+			served:   true,
+			reason:   popv1beta1.VolumePopulatorReasonKindServed,
+		},
+		{
+			name:     "CRD does not serve required version",
+			gk:       metav1.GroupKind{Group: "crd.storage.k8s.io", Kind: "Served"},
+			versions: []string{"v1", "v1beta1"},
+			served:   false,
+			reason:   popv1beta1.VolumePopulatorReasonVersionsNotServed,
+		},
+		{
+			name:   "CRD not established",
+			gk:     metav1.GroupKind{Group: "crd.storage.k8s.io", Kind: "Pending"},
+			served: false,
+			reason: popv1beta1.VolumePopulatorReasonKindNotServed,
+		},
+		{
+			name:   "Served by discovery",
+			gk:     metav1.GroupKind{Group: "aggregated.storage.k8s.io", Kind: "Image"},
+			served: true,
+			reason: popv1beta1.VolumePopulatorReasonKindServed,
+		},
+		{
+			name:   "Not served",
+			gk:     metav1.GroupKind{Group: "missing.storage.k8s.io", Kind: "Missing"},
+			served: false,
+			reason: popv1beta1.VolumePopulatorReasonKindNotServed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			populator := makePopulator("populator", tc.gk.Group, tc.gk.Kind)
+			populator.RequiredVersions = tc.versions
+			served, reason, _, err := ctrl.sourceKindServed(populator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if served != tc.served {
+				t.Errorf(`expected "%v" to equal "%v"`, served, tc.served)
+			}
+			if reason != tc.reason {
+				t.Errorf(`expected "%v" to equal "%v"`, reason, tc.reason)
+			}
+		})
+	}
+}
+
+func TestValidateGroupKindNotServed(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{crdGroupKindIndex: crdGroupKindIndexFunc})
+	client := fake.NewSimpleClientset()
+	ctrl := &populatorController{
+		crdIndexer: indexer,
+		discovery:  memory.NewMemCacheClient(client.Discovery()),
+		metrics:    new(FakeMetricsManager),
+		popLister:  makeFakeLister(makePopulator("valid", "valid.storage.k8s.io", "Valid")),
+	}
+
+	result, err := ctrl.validateSpec("default", dataSourceSpec(metav1.GroupKind{Group: "valid.storage.k8s.io", Kind: "Valid"}))
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if result.Valid {
+		t.Error("expected invalid")
+	}
+	if result.Reason != "DataSourceKindNotServed" {
+		t.Errorf(`expected "%v" to equal "DataSourceKindNotServed"`, result.Reason)
+	}
+}
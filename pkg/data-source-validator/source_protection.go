@@ -0,0 +1,330 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// SourceProtectionFinalizer is added to the data sources of Pending PVCs
+// when source protection is enabled. When source protection is disabled,
+// the finalizer is no longer removed; see the README for how to remove it.
+const SourceProtectionFinalizer = "datasource-validator.storage.k8s.io/in-use"
+
+// pvcSourceIndex indexes PVCs by the key of their data source.
+const pvcSourceIndex = "source"
+
+// WithSourceProtection makes the controller add SourceProtectionFinalizer to
+// the data sources of Pending PVCs, and remove it once every PVC using the
+// source is Bound or deleted. Only sources in the namespace of the PVC, or
+// cluster-scoped ones, of a kind the controller accepts are protected.
+// On startup, the finalizer is removed from sources that are no longer in
+// use. mapper maps source kinds to the resources patched through the
+// dynamic client.
+func WithSourceProtection(mapper meta.RESTMapper) Option {
+	return func(ctrl *populatorController) {
+		err := ctrl.pvcIndexer.AddIndexers(cache.Indexers{pvcSourceIndex: pvcSourceIndexFunc})
+		if err != nil {
+			klog.Fatalf("Failed to add pvc index: %v", err)
+		}
+		ctrl.mapper = mapper
+		ctrl.sourceQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "source")
+	}
+}
+
+// sourceKey returns the key of the data source of a PVC, or "" if the PVC
+// has no data source that can be protected.
+func sourceKey(pvc *v1.PersistentVolumeClaim) string {
+	dataSourceRef := pvc.Spec.DataSourceRef
+	if dataSourceRef == nil {
+		return ""
+	}
+	if dataSourceRef.Namespace != nil && *dataSourceRef.Namespace != "" && *dataSourceRef.Namespace != pvc.Namespace {
+		// Cross-namespace sources are guarded by ReferenceGrants,
+		// which the controller does not check.
+		return ""
+	}
+	gk := dataSourceGroupKind(pvc)
+	return strings.Join([]string{gk.Group, gk.Kind, pvc.Namespace, dataSourceRef.Name}, "/")
+}
+
+func pvcSourceIndexFunc(obj interface{}) ([]string, error) {
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return nil, nil
+	}
+	if key := sourceKey(pvc); key != "" {
+		return []string{key}, nil
+	}
+	return nil, nil
+}
+
+// enqueueSource queues the data source of a PVC.
+func (ctrl *populatorController) enqueueSource(pvc *v1.PersistentVolumeClaim) {
+	if key := sourceKey(pvc); key != "" {
+		klog.V(5).Infof("enqueued source %q for sync", key)
+		ctrl.sourceQueue.Add(key)
+	}
+}
+
+// sourceWorker is the main worker for data sources.
+func (ctrl *populatorController) sourceWorker() {
+	keyObj, quit := ctrl.sourceQueue.Get()
+	if quit {
+		return
+	}
+	defer ctrl.sourceQueue.Done(keyObj)
+
+	if err := ctrl.syncSourceByKey(keyObj.(string)); err != nil {
+		ctrl.sourceQueue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to sync source %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		ctrl.sourceQueue.Forget(keyObj)
+	}
+}
+
+// syncSourceByKey adds or removes the finalizer of a data source, depending
+// on whether a Pending PVC uses it.
+func (ctrl *populatorController) syncSourceByKey(key string) error {
+	klog.V(5).Infof("syncSourceByKey[%s]", key)
+
+	parts := strings.Split(key, "/")
+	if len(parts) != 4 {
+		klog.Errorf("invalid source key %q", key)
+		return nil
+	}
+	gk := metav1.GroupKind{Group: parts[0], Kind: parts[1]}
+	namespace, name := parts[2], parts[3]
+
+	mapping, err := ctrl.restMapping(gk)
+	if err != nil {
+		if !meta.IsNoMatchError(err) {
+			return err
+		}
+		klog.V(4).Infof("Cannot protect %s %s/%s: %v", gk.String(), namespace, name, err)
+		return nil
+	}
+	clusterScoped := mapping.Scope.Name() == meta.RESTScopeNameRoot
+	if clusterScoped {
+		namespace = ""
+	}
+
+	inUse, err := ctrl.sourceInUse(gk, namespace, name)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		supported, err := ctrl.supportedSourceKind(gk)
+		if err != nil {
+			return err
+		}
+		if !supported {
+			return nil
+		}
+	}
+	return ctrl.setSourceFinalizer(mapping, gk, namespace, name, inUse)
+}
+
+// sourceInUse returns whether a Pending PVC uses a data source. A source
+// without a namespace is cluster-scoped and used by PVCs of any namespace.
+func (ctrl *populatorController) sourceInUse(gk metav1.GroupKind, namespace, name string) (bool, error) {
+	keys := []string{strings.Join([]string{gk.Group, gk.Kind, namespace, name}, "/")}
+	if namespace == "" {
+		keys = nil
+		for _, key := range ctrl.pvcIndexer.ListIndexFuncValues(pvcSourceIndex) {
+			parts := strings.Split(key, "/")
+			if len(parts) == 4 && parts[0] == gk.Group && parts[1] == gk.Kind && parts[3] == name {
+				keys = append(keys, key)
+			}
+		}
+	}
+	for _, key := range keys {
+		objs, err := ctrl.pvcIndexer.ByIndex(pvcSourceIndex, key)
+		if err != nil {
+			return false, err
+		}
+		for _, obj := range objs {
+			pvc := obj.(*v1.PersistentVolumeClaim)
+			if pvc.Status.Phase == v1.ClaimPending && pvc.DeletionTimestamp == nil {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// supportedSourceKind returns whether PVCs may use the kind as data source,
+// like the validator does, but without reporting anything.
+func (ctrl *populatorController) supportedSourceKind(gk metav1.GroupKind) (bool, error) {
+	if gk == pvcGK || gk == volumeSnapshotGK {
+		return true, nil
+	}
+	populators, err := ctrl.listPopulators()
+	if err != nil {
+		return false, err
+	}
+	return len(validation.MatchPopulators(populators, gk)) > 0, nil
+}
+
+// restMapping maps a source kind to its resource. The mapper given to the
+// options should be a validation.NewRefreshingRESTMapper, so that kinds
+// installed after startup are found.
+func (ctrl *populatorController) restMapping(gk metav1.GroupKind) (*meta.RESTMapping, error) {
+	return ctrl.mapper.RESTMapping(schema.GroupKind{Group: gk.Group, Kind: gk.Kind})
+}
+
+// setSourceFinalizer adds or removes the finalizer of a data source. The
+// finalizers are patched, with the resourceVersion of the object that was
+// read, so that concurrent changes by other controllers are not lost.
+func (ctrl *populatorController) setSourceFinalizer(mapping *meta.RESTMapping, gk metav1.GroupKind, namespace, name string, present bool) error {
+	var client dynamic.ResourceInterface = ctrl.dynClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		client = ctrl.dynClient.Resource(mapping.Resource).Namespace(namespace)
+	}
+	source, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	finalizers := source.GetFinalizers()
+	has := hasFinalizer(finalizers, SourceProtectionFinalizer)
+	switch {
+	case present && !has:
+		if source.GetDeletionTimestamp() != nil {
+			// Too late to protect the source.
+			return nil
+		}
+		finalizers = append(finalizers, SourceProtectionFinalizer)
+	case !present && has:
+		var remaining []string
+		for _, finalizer := range finalizers {
+			if finalizer != SourceProtectionFinalizer {
+				remaining = append(remaining, finalizer)
+			}
+		}
+		finalizers = remaining
+	default:
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": source.GetResourceVersion(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := client.Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to patch finalizers of %s %s: %w", gk.String(), describeSource(namespace, name), err)
+	}
+	if present {
+		klog.V(4).Infof("Added finalizer to %s %s", gk.String(), describeSource(namespace, name))
+	} else {
+		klog.V(4).Infof("Removed finalizer from %s %s", gk.String(), describeSource(namespace, name))
+	}
+	return nil
+}
+
+// describeSource returns namespace/name, or name for cluster-scoped
+// sources.
+func describeSource(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// enqueueProtectedSources queues every object that carries
+// SourceProtectionFinalizer, of the kinds that PVCs can use as a data
+// source, so that the finalizer is removed from sources whose PVCs were
+// deleted or bound while the controller was not running. Sources of kinds
+// that are neither built in, registered, nor used by a PVC are not found.
+func (ctrl *populatorController) enqueueProtectedSources() {
+	kinds := sets.New(pvcGK, volumeSnapshotGK)
+	if populators, err := ctrl.listPopulators(); err == nil {
+		for _, populator := range populators {
+			if !validation.IsWildcard(populator) {
+				kinds.Insert(populator.SourceKind)
+			}
+		}
+	}
+	if ctrl.nsPopLister != nil {
+		if nsPopulators, err := ctrl.nsPopLister.List(labels.Everything()); err == nil {
+			for _, nsPopulator := range nsPopulators {
+				group, _, _ := unstructured.NestedString(nsPopulator.Object, "sourceKind", "group")
+				kind, _, _ := unstructured.NestedString(nsPopulator.Object, "sourceKind", "kind")
+				kinds.Insert(metav1.GroupKind{Group: group, Kind: kind})
+			}
+		}
+	}
+	for _, key := range ctrl.pvcIndexer.ListIndexFuncValues(pvcSourceIndex) {
+		if parts := strings.Split(key, "/"); len(parts) == 4 {
+			kinds.Insert(metav1.GroupKind{Group: parts[0], Kind: parts[1]})
+		}
+	}
+
+	for gk := range kinds {
+		mapping, err := ctrl.restMapping(gk)
+		if err != nil {
+			klog.V(4).Infof("Cannot look for protected %s: %v", gk.String(), err)
+			continue
+		}
+		options := metav1.ListOptions{Limit: 500}
+		for {
+			list, err := ctrl.dynClient.Resource(mapping.Resource).List(context.TODO(), options)
+			if err != nil {
+				klog.Errorf("Failed to list %s: %v", gk.String(), err)
+				break
+			}
+			for _, item := range list.Items {
+				if hasFinalizer(item.GetFinalizers(), SourceProtectionFinalizer) {
+					key := strings.Join([]string{gk.Group, gk.Kind, item.GetNamespace(), item.GetName()}, "/")
+					klog.V(5).Infof("enqueued protected source %q for sync", key)
+					ctrl.sourceQueue.Add(key)
+				}
+			}
+			if list.GetContinue() == "" {
+				break
+			}
+			options.Continue = list.GetContinue()
+		}
+	}
+}
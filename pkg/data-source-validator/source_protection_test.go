@@ -0,0 +1,241 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamiclister"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+var sampleGVR = schema.GroupVersionResource{Group: "valid.storage.k8s.io", Version: "v1", Resource: "valids"}
+
+func makeSource(name string, finalizers ...string) *unstructured.Unstructured {
+	source := &unstructured.Unstructured{}
+	source.SetAPIVersion("valid.storage.k8s.io/v1")
+	source.SetKind("Valid")
+	source.SetNamespace("default")
+	source.SetName(name)
+	source.SetFinalizers(finalizers)
+	return source
+}
+
+func makeSourcePVC(name, source string, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+	pvc := makePVC(name, 0, phase)
+	pvc.Spec.DataSourceRef = &v1.TypedObjectReference{
+		APIGroup: ptr.To("valid.storage.k8s.io"),
+		Kind:     "Valid",
+		Name:     source,
+	}
+	return pvc
+}
+
+func TestSyncSource(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{sampleGVR.GroupVersion()})
+	mapper.Add(sampleGVR.GroupVersion().WithKind("Valid"), meta.RESTScopeNamespace)
+
+	testCases := []struct {
+		name       string
+		populators bool
+		source     *unstructured.Unstructured
+		pvcs       []*v1.PersistentVolumeClaim
+		expected   []string
+	}{
+		{
+			name:       "Pending PVC adds finalizer",
+			populators: true,
+			source:     makeSource("source", "other"),
+			pvcs:       []*v1.PersistentVolumeClaim{makeSourcePVC("pvc", "source", v1.ClaimPending)},
+			expected:   []string{"other", SourceProtectionFinalizer},
+		},
+		{
+			name:       "Bound PVC removes finalizer",
+			populators: true,
+			source:     makeSource("source", SourceProtectionFinalizer, "other"),
+			pvcs:       []*v1.PersistentVolumeClaim{makeSourcePVC("pvc", "source", v1.ClaimBound)},
+			expected:   []string{"other"},
+		},
+		{
+			name:       "Deleted PVC removes finalizer",
+			populators: true,
+			source:     makeSource("source", SourceProtectionFinalizer),
+		},
+		{
+			name:       "One of two PVCs Pending",
+			populators: true,
+			source:     makeSource("source", SourceProtectionFinalizer),
+			pvcs: []*v1.PersistentVolumeClaim{
+				makeSourcePVC("bound", "source", v1.ClaimBound),
+				makeSourcePVC("pending", "source", v1.ClaimPending),
+			},
+			expected: []string{SourceProtectionFinalizer},
+		},
+		{
+			name:     "Unregistered kind is not protected",
+			source:   makeSource("source"),
+			pvcs:     []*v1.PersistentVolumeClaim{makeSourcePVC("pvc", "source", v1.ClaimPending)},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{sampleGVR: "ValidList"}, tc.source)
+			var populators dynamiclister.Lister
+			if tc.populators {
+				populators = makeFakeLister(makePopulator("populator", "valid.storage.k8s.io", "Valid"))
+			} else {
+				populators = makeFakeLister()
+			}
+			ctrl := &populatorController{
+				dynClient:  client,
+				popLister:  populators,
+				pvcIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+			}
+			WithSourceProtection(mapper)(ctrl)
+			defer ctrl.sourceQueue.ShutDown()
+			for _, pvc := range tc.pvcs {
+				ctrl.pvcIndexer.Add(pvc)
+			}
+
+			if err := ctrl.syncSourceByKey("valid.storage.k8s.io/Valid/default/source"); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			source, err := client.Resource(sampleGVR).Namespace("default").Get(context.TODO(), "source", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			finalizers := source.GetFinalizers()
+			if len(finalizers) == 0 && len(tc.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(finalizers, tc.expected) {
+				t.Errorf(`expected "%v" to equal "%v"`, finalizers, tc.expected)
+			}
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "update" {
+					t.Errorf(`expected finalizers to be patched, got "%v"`, action)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncClusterScopedSource(t *testing.T) {
+	clusterGVR := schema.GroupVersionResource{Group: "cluster.storage.k8s.io", Version: "v1", Resource: "images"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{clusterGVR.GroupVersion()})
+	mapper.Add(clusterGVR.GroupVersion().WithKind("Image"), meta.RESTScopeRoot)
+	image := &unstructured.Unstructured{}
+	image.SetAPIVersion("cluster.storage.k8s.io/v1")
+	image.SetKind("Image")
+	image.SetName("golden")
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{clusterGVR: "ImageList"}, image)
+	ctrl := &populatorController{
+		dynClient:  client,
+		popLister:  makeFakeLister(makePopulator("populator", "cluster.storage.k8s.io", "Image")),
+		pvcIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+	}
+	WithSourceProtection(mapper)(ctrl)
+	defer ctrl.sourceQueue.ShutDown()
+	pending := makeSourcePVC("pending", "golden", v1.ClaimPending)
+	pending.Namespace = "tenant-a"
+	pending.Spec.DataSourceRef = &v1.TypedObjectReference{APIGroup: ptr.To("cluster.storage.k8s.io"), Kind: "Image", Name: "golden"}
+	bound := pending.DeepCopy()
+	bound.Namespace = "tenant-b"
+	bound.Status.Phase = v1.ClaimBound
+	ctrl.pvcIndexer.Add(pending)
+	ctrl.pvcIndexer.Add(bound)
+
+	// The Bound PVC of tenant-b does not release the source used by the
+	// Pending PVC of tenant-a.
+	for _, key := range []string{"cluster.storage.k8s.io/Image/tenant-a/golden", "cluster.storage.k8s.io/Image/tenant-b/golden"} {
+		if err := ctrl.syncSourceByKey(key); err != nil {
+			t.Fatalf(`expected nil error, got "%v"`, err)
+		}
+	}
+	source, err := client.Resource(clusterGVR).Get(context.TODO(), "golden", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if expected := []string{SourceProtectionFinalizer}; !reflect.DeepEqual(source.GetFinalizers(), expected) {
+		t.Errorf(`expected "%v" to equal "%v"`, source.GetFinalizers(), expected)
+	}
+}
+
+func TestEnqueueProtectedSources(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{sampleGVR.GroupVersion()})
+	mapper.Add(sampleGVR.GroupVersion().WithKind("Valid"), meta.RESTScopeNamespace)
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{sampleGVR: "ValidList"},
+		makeSource("protected", SourceProtectionFinalizer),
+		makeSource("unprotected", "other"),
+	)
+	ctrl := &populatorController{
+		dynClient:  client,
+		popLister:  makeFakeLister(makePopulator("populator", "valid.storage.k8s.io", "Valid")),
+		pvcIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+	}
+	WithSourceProtection(mapper)(ctrl)
+	defer ctrl.sourceQueue.ShutDown()
+
+	ctrl.enqueueProtectedSources()
+	if ctrl.sourceQueue.Len() != 1 {
+		t.Fatalf(`expected "%v" to equal "%v"`, ctrl.sourceQueue.Len(), 1)
+	}
+	key, _ := ctrl.sourceQueue.Get()
+	if key != "valid.storage.k8s.io/Valid/default/protected" {
+		t.Errorf(`expected "%v" to equal "%v"`, key, "valid.storage.k8s.io/Valid/default/protected")
+	}
+	ctrl.sourceQueue.Done(key)
+
+	// Without a Pending PVC, the sync removes the finalizer.
+	if err := ctrl.syncSourceByKey(key.(string)); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	source, err := client.Resource(sampleGVR).Namespace("default").Get(context.TODO(), "protected", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if len(source.GetFinalizers()) != 0 {
+		t.Errorf(`expected no finalizers, got "%v"`, source.GetFinalizers())
+	}
+}
+
+func TestSourceKey(t *testing.T) {
+	pvc := makeSourcePVC("pvc", "source", v1.ClaimPending)
+	if key := sourceKey(pvc); key != "valid.storage.k8s.io/Valid/default/source" {
+		t.Errorf(`unexpected key "%v"`, key)
+	}
+	pvc.Spec.DataSourceRef.Namespace = ptr.To("other")
+	if key := sourceKey(pvc); key != "" {
+		t.Errorf(`expected no key for a cross-namespace source, got "%v"`, key)
+	}
+}
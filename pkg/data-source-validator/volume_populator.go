@@ -0,0 +1,333 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// enqueueAllPopulators adds every known VolumePopulator to the populator
+// work queue.
+func (ctrl *populatorController) enqueueAllPopulators() {
+	unstPopulators, err := ctrl.popLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list populators: %v", err)
+		return
+	}
+	for _, unstPopulator := range unstPopulators {
+		klog.V(5).Infof("enqueued populator %q for sync", unstPopulator.GetName())
+		ctrl.popQueue.Add(unstPopulator.GetName())
+	}
+}
+
+// popWorker is the main worker for VolumePopulators.
+func (ctrl *populatorController) popWorker() {
+	keyObj, quit := ctrl.popQueue.Get()
+	if quit {
+		return
+	}
+	defer ctrl.popQueue.Done(keyObj)
+
+	if err := ctrl.syncPopulatorByKey(keyObj.(string)); err != nil {
+		ctrl.popQueue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to sync populator %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		ctrl.popQueue.Forget(keyObj)
+	}
+}
+
+// syncPopulatorByKey re-evaluates the registration of a single VolumePopulator
+// against all the others and records the result in its status.
+func (ctrl *populatorController) syncPopulatorByKey(name string) error {
+	klog.V(5).Infof("syncPopulatorByKey[%s]", name)
+
+	unstPopulator, err := ctrl.popLister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			utilruntime.HandleError(fmt.Errorf("populator '%s' in work queue no longer exists", name))
+			return nil
+		}
+		return err
+	}
+	populator, err := validation.ConvertPopulator(unstPopulator)
+	if err != nil {
+		return err
+	}
+	populators, err := ctrl.listPopulators()
+	if err != nil {
+		return err
+	}
+
+	if ctrl.populatorProtection {
+		deleted, err := ctrl.protectPopulator(unstPopulator, populator, populators)
+		if err != nil || deleted {
+			return err
+		}
+	} else if hasFinalizer(populator.Finalizers, PopulatorProtectionFinalizer) {
+		// Left behind while populator protection was enabled. The
+		// patch triggers another sync.
+		return ctrl.releasePopulator(unstPopulator, populator)
+	}
+
+	conflicts := findConflicts(populators)
+	ctrl.metrics.SetPopulatorConflicts(len(conflicts))
+
+	wasConflicting := populator.Status != nil &&
+		meta.IsStatusConditionTrue(populator.Status.Conditions, popv1beta1.VolumePopulatorConflicting)
+	others := conflicts[populator.Name]
+	if len(others) > 0 && !wasConflicting {
+		ctrl.eventRecorder.Eventf(populator, v1.EventTypeWarning, "ConflictingVolumePopulator",
+			"Source kind %s is also registered by VolumePopulator %s", populator.SourceKind.String(), strings.Join(others, ", "))
+	}
+
+	conditions := []metav1.Condition{conflictCondition(populator, others)}
+	var removed []string
+	if ctrl.crdIndexer != nil {
+		condition, err := ctrl.sourceKindServedCondition(populator)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, condition)
+	} else {
+		removed = append(removed, popv1beta1.VolumePopulatorSourceKindServed)
+	}
+	if ctrl.leases != nil && populator.ControllerLease != nil {
+		condition, err := ctrl.availableCondition(populator)
+		if err != nil {
+			return err
+		}
+		wasAvailable := populator.Status == nil ||
+			!meta.IsStatusConditionFalse(populator.Status.Conditions, popv1beta1.VolumePopulatorAvailable)
+		if wasAvailable && condition.Status == metav1.ConditionFalse {
+			klog.V(2).Infof("Populator %q became unavailable: %s", populator.Name, condition.Message)
+			ctrl.enqueuePendingPVCs(populator.SourceKind)
+		}
+		conditions = append(conditions, condition)
+	} else {
+		removed = append(removed, popv1beta1.VolumePopulatorAvailable)
+	}
+	wasSuspended := populator.Status != nil &&
+		meta.IsStatusConditionTrue(populator.Status.Conditions, popv1beta1.VolumePopulatorSuspended)
+	if wasSuspended != populator.Suspended {
+		// Pending PVCs are flagged, or no longer flagged, straight away.
+		klog.V(2).Infof("Populator %q suspended: %v", populator.Name, populator.Suspended)
+		ctrl.enqueuePendingPVCs(populator.SourceKind)
+	}
+	if populator.Suspended {
+		conditions = append(conditions, suspendedCondition(populator))
+	} else {
+		removed = append(removed, popv1beta1.VolumePopulatorSuspended)
+	}
+	if len(populator.ValidationRules) > 0 {
+		condition := validationRulesCondition(populator, ctrl.pluginEnabled(validation.ValidationRulesPluginName))
+		wasValid := populator.Status == nil ||
+			!meta.IsStatusConditionFalse(populator.Status.Conditions, popv1beta1.VolumePopulatorValidationRulesValid)
+		if wasValid && condition.Status == metav1.ConditionFalse {
+			reason := "InvalidValidationRule"
+			if condition.Reason == popv1beta1.VolumePopulatorReasonRulesNotEnforced {
+				reason = "ValidationRulesNotEnforced"
+			}
+			ctrl.eventRecorder.Event(populator, v1.EventTypeWarning, reason, condition.Message)
+		}
+		conditions = append(conditions, condition)
+	} else {
+		removed = append(removed, popv1beta1.VolumePopulatorValidationRulesValid)
+	}
+	if populator.ValidationWebhook != nil {
+		condition := validationWebhookCondition(populator, ctrl.pluginEnabled(validation.PopulatorWebhookPluginName))
+		wasEnabled := populator.Status == nil ||
+			!meta.IsStatusConditionFalse(populator.Status.Conditions, popv1beta1.VolumePopulatorValidationWebhookEnabled)
+		if wasEnabled && condition.Status == metav1.ConditionFalse {
+			ctrl.eventRecorder.Event(populator, v1.EventTypeWarning, "ValidationWebhookNotEnabled", condition.Message)
+		}
+		conditions = append(conditions, condition)
+	} else {
+		removed = append(removed, popv1beta1.VolumePopulatorValidationWebhookEnabled)
+	}
+
+	return ctrl.updatePopulatorConditions(populator, conditions, removed)
+}
+
+// conflictCondition builds the Conflicting condition of a populator, given
+// the names of the other populators that register the same source kind.
+func conflictCondition(populator *popv1beta1.VolumePopulator, others []string) metav1.Condition {
+	if len(others) > 0 {
+		return metav1.Condition{
+			Type:               popv1beta1.VolumePopulatorConflicting,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: populator.Generation,
+			Reason:             popv1beta1.VolumePopulatorReasonDuplicateSourceKind,
+			Message:            fmt.Sprintf("Source kind %s is also registered by %s", populator.SourceKind.String(), strings.Join(others, ", ")),
+		}
+	}
+	return metav1.Condition{
+		Type:               popv1beta1.VolumePopulatorConflicting,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: populator.Generation,
+		Reason:             popv1beta1.VolumePopulatorReasonUniqueSourceKind,
+		Message:            fmt.Sprintf("No other VolumePopulator registers source kind %s", populator.SourceKind.String()),
+	}
+}
+
+// suspendedCondition builds the Suspended condition of a suspended
+// populator.
+func suspendedCondition(populator *popv1beta1.VolumePopulator) metav1.Condition {
+	message := "Suspended without a reason"
+	if populator.SuspendReason != "" {
+		message = populator.SuspendReason
+	}
+	return metav1.Condition{
+		Type:               popv1beta1.VolumePopulatorSuspended,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: populator.Generation,
+		Reason:             popv1beta1.VolumePopulatorReasonSuspendedByUser,
+		Message:            message,
+	}
+}
+
+// validationRulesCondition builds the ValidationRulesValid condition of a
+// populator with validation rules. enforced says whether the validator
+// evaluates the rules, with the ValidationRules plugin.
+func validationRulesCondition(populator *popv1beta1.VolumePopulator, enforced bool) metav1.Condition {
+	if err := validation.CompileRules(populator); err != nil {
+		return metav1.Condition{
+			Type:               popv1beta1.VolumePopulatorValidationRulesValid,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: populator.Generation,
+			Reason:             popv1beta1.VolumePopulatorReasonRuleCompileError,
+			Message:            err.Error(),
+		}
+	}
+	if !enforced {
+		return metav1.Condition{
+			Type:               popv1beta1.VolumePopulatorValidationRulesValid,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: populator.Generation,
+			Reason:             popv1beta1.VolumePopulatorReasonRulesNotEnforced,
+			Message: fmt.Sprintf("All %d validation rules compile, but are not evaluated: the validator runs without the %s plugin",
+				len(populator.ValidationRules), validation.ValidationRulesPluginName),
+		}
+	}
+	return metav1.Condition{
+		Type:               popv1beta1.VolumePopulatorValidationRulesValid,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: populator.Generation,
+		Reason:             popv1beta1.VolumePopulatorReasonRulesCompiled,
+		Message:            fmt.Sprintf("All %d validation rules compile", len(populator.ValidationRules)),
+	}
+}
+
+// validationWebhookCondition builds the ValidationWebhookEnabled condition
+// of a populator with a validation webhook. enabled says whether the
+// validator calls the webhook, with the PopulatorWebhook plugin.
+func validationWebhookCondition(populator *popv1beta1.VolumePopulator, enabled bool) metav1.Condition {
+	if !enabled {
+		return metav1.Condition{
+			Type:               popv1beta1.VolumePopulatorValidationWebhookEnabled,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: populator.Generation,
+			Reason:             popv1beta1.VolumePopulatorReasonWebhookNotEnabled,
+			Message:            fmt.Sprintf("The validation webhook is not called: the validator runs without the %s plugin", validation.PopulatorWebhookPluginName),
+		}
+	}
+	return metav1.Condition{
+		Type:               popv1beta1.VolumePopulatorValidationWebhookEnabled,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: populator.Generation,
+		Reason:             popv1beta1.VolumePopulatorReasonWebhookEnabled,
+		Message:            "The validation webhook is called for PVCs",
+	}
+}
+
+// findConflicts returns, for every populator whose source kind is registered
+// more than once, the names of the other populators registering that kind.
+// Populators without conflicts are not included in the result.
+func findConflicts(populators []*popv1beta1.VolumePopulator) map[string][]string {
+	byKind := make(map[metav1.GroupKind][]string)
+	for _, populator := range populators {
+		byKind[populator.SourceKind] = append(byKind[populator.SourceKind], populator.Name)
+	}
+	conflicts := make(map[string][]string)
+	for _, names := range byKind {
+		if len(names) < 2 {
+			continue
+		}
+		for _, name := range names {
+			for _, other := range names {
+				if other != name {
+					conflicts[name] = append(conflicts[name], other)
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// updatePopulatorConditions sets the given conditions on the populator,
+// removes the conditions of the removed types and writes its status if
+// anything changed. Clusters where the installed CRD has no status
+// subresource are tolerated.
+func (ctrl *populatorController) updatePopulatorConditions(populator *popv1beta1.VolumePopulator, conditions []metav1.Condition, removed []string) error {
+	populator = populator.DeepCopy()
+	if populator.Status == nil {
+		populator.Status = &popv1beta1.VolumePopulatorStatus{}
+	}
+	changed := false
+	for _, condition := range conditions {
+		if meta.SetStatusCondition(&populator.Status.Conditions, condition) {
+			changed = true
+		}
+	}
+	for _, conditionType := range removed {
+		if meta.RemoveStatusCondition(&populator.Status.Conditions, conditionType) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(populator)
+	if err != nil {
+		return err
+	}
+	_, err = ctrl.dynClient.Resource(PopulatorResource).UpdateStatus(context.TODO(), &unstructured.Unstructured{Object: content}, metav1.UpdateOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+			klog.V(4).Infof("Cannot update status of populator %q, the installed CRD may not have a status subresource: %v", populator.Name, err)
+			return nil
+		}
+		return err
+	}
+	klog.V(4).Infof("Updated status of populator %q", populator.Name)
+	return nil
+}
@@ -0,0 +1,406 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+func makePopulator(name, group, kind string) *popv1beta1.VolumePopulator {
+	return &popv1beta1.VolumePopulator{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VolumePopulator",
+			APIVersion: popv1beta1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		SourceKind: metav1.GroupKind{
+			Group: group,
+			Kind:  kind,
+		},
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	testCases := []struct {
+		name       string
+		populators []*popv1beta1.VolumePopulator
+		expected   map[string][]string
+	}{
+		{
+			name: "No conflicts",
+			populators: []*popv1beta1.VolumePopulator{
+				makePopulator("a", "a.storage.k8s.io", "A"),
+				makePopulator("b", "b.storage.k8s.io", "B"),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "Same kind in different groups",
+			populators: []*popv1beta1.VolumePopulator{
+				makePopulator("a", "a.storage.k8s.io", "Image"),
+				makePopulator("b", "b.storage.k8s.io", "Image"),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "Duplicate registrations",
+			populators: []*popv1beta1.VolumePopulator{
+				makePopulator("a", "a.storage.k8s.io", "A"),
+				makePopulator("b", "a.storage.k8s.io", "A"),
+				makePopulator("c", "a.storage.k8s.io", "A"),
+				makePopulator("d", "d.storage.k8s.io", "D"),
+			},
+			expected: map[string][]string{
+				"a": {"b", "c"},
+				"b": {"a", "c"},
+				"c": {"a", "b"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conflicts := findConflicts(tc.populators)
+			if !reflect.DeepEqual(conflicts, tc.expected) {
+				t.Errorf(`expected "%v" to equal "%v"`, conflicts, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSyncPopulatorConflicts(t *testing.T) {
+	client, lister := makeFakeClient(
+		makePopulator("first", "valid.storage.k8s.io", "Valid"),
+		makePopulator("second", "valid.storage.k8s.io", "Valid"),
+		makePopulator("other", "other.storage.k8s.io", "Other"),
+	)
+	recorder := record.NewFakeRecorder(10)
+	ctrl := &populatorController{
+		dynClient:     client,
+		eventRecorder: recorder,
+		popLister:     lister,
+		metrics:       new(FakeMetricsManager),
+	}
+
+	testCases := []struct {
+		name        string
+		conflicting bool
+		event       string
+	}{
+		{
+			name:        "first",
+			conflicting: true,
+			event:       "Warning ConflictingVolumePopulator Source kind Valid.valid.storage.k8s.io is also registered by VolumePopulator second",
+		},
+		{
+			name:        "second",
+			conflicting: true,
+			event:       "Warning ConflictingVolumePopulator Source kind Valid.valid.storage.k8s.io is also registered by VolumePopulator first",
+		},
+		{
+			name:        "other",
+			conflicting: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ctrl.syncPopulatorByKey(tc.name); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			unstPopulator, err := client.Resource(PopulatorResource).Get(context.TODO(), tc.name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			populator, err := validation.ConvertPopulator(unstPopulator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if populator.Status == nil {
+				t.Fatalf("expected status to be set")
+			}
+			conflicting := meta.IsStatusConditionTrue(populator.Status.Conditions, popv1beta1.VolumePopulatorConflicting)
+			if conflicting != tc.conflicting {
+				t.Errorf(`expected "%v" to equal "%v"`, conflicting, tc.conflicting)
+			}
+			select {
+			case event := <-recorder.Events:
+				if tc.event == "" {
+					t.Errorf(`unexpected event "%s"`, event)
+				} else if !strings.HasPrefix(event, tc.event) {
+					t.Errorf(`expected "%s" to equal "%s"`, event, tc.event)
+				}
+			default:
+				if tc.event != "" {
+					t.Errorf(`expected event "%s"`, tc.event)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncPopulatorValidationRules(t *testing.T) {
+	valid := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	valid.ValidationRules = []popv1beta1.ValidationRule{
+		{Rule: "source == null || quantityValue(pvc.spec.resources.requests.storage) >= quantityValue(source.spec.virtualSize)", Message: "too small"},
+	}
+	invalid := makePopulator("invalid", "invalid.storage.k8s.io", "Invalid")
+	invalid.ValidationRules = []popv1beta1.ValidationRule{
+		{Rule: "pvc.spec.volumeMode == 'Block'", Message: "block only"},
+		{Rule: "pvc.spec.volumeMode ==", Message: "broken"},
+	}
+	unenforced := makePopulator("unenforced", "unenforced.storage.k8s.io", "Unenforced")
+	unenforced.ValidationRules = valid.ValidationRules
+	client, lister := makeFakeClient(valid, invalid, unenforced, makePopulator("none", "none.storage.k8s.io", "None"))
+	plugins, err := validation.DefaultRegistry.Build([]string{validation.ValidationRulesPluginName}, validation.Handle{
+		Client:        fake.NewSimpleClientset(),
+		DynamicClient: client,
+		Mapper:        meta.NewDefaultRESTMapper(nil),
+	})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	ctrl := &populatorController{
+		dynClient:     client,
+		eventRecorder: recorder,
+		popLister:     lister,
+		metrics:       new(FakeMetricsManager),
+	}
+
+	testCases := []struct {
+		name       string
+		unenforced bool
+		status     metav1.ConditionStatus
+		reason     string
+		event      string
+	}{
+		{
+			name:   "valid",
+			status: metav1.ConditionTrue,
+			reason: popv1beta1.VolumePopulatorReasonRulesCompiled,
+		},
+		{
+			name:   "invalid",
+			status: metav1.ConditionFalse,
+			reason: popv1beta1.VolumePopulatorReasonRuleCompileError,
+			event:  "Warning InvalidValidationRule validationRules[1]: ",
+		},
+		{
+			name:       "unenforced",
+			unenforced: true,
+			status:     metav1.ConditionFalse,
+			reason:     popv1beta1.VolumePopulatorReasonRulesNotEnforced,
+			event:      "Warning ValidationRulesNotEnforced All 1 validation rules compile, but are not evaluated",
+		},
+		{
+			name: "none",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl.plugins = plugins
+			if tc.unenforced {
+				ctrl.plugins = nil
+			}
+			if err := ctrl.syncPopulatorByKey(tc.name); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			unstPopulator, err := client.Resource(PopulatorResource).Get(context.TODO(), tc.name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			populator, err := validation.ConvertPopulator(unstPopulator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			var status metav1.ConditionStatus
+			var reason string
+			if condition := meta.FindStatusCondition(populator.Status.Conditions, popv1beta1.VolumePopulatorValidationRulesValid); condition != nil {
+				status = condition.Status
+				reason = condition.Reason
+			}
+			if status != tc.status {
+				t.Errorf(`expected "%v" to equal "%v"`, status, tc.status)
+			}
+			if reason != tc.reason {
+				t.Errorf(`expected "%v" to equal "%v"`, reason, tc.reason)
+			}
+			select {
+			case event := <-recorder.Events:
+				if tc.event == "" {
+					t.Errorf(`unexpected event "%s"`, event)
+				} else if !strings.HasPrefix(event, tc.event) {
+					t.Errorf(`expected "%s" to equal "%s"`, event, tc.event)
+				}
+			default:
+				if tc.event != "" {
+					t.Errorf(`expected event "%s"`, tc.event)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncPopulatorValidationWebhook(t *testing.T) {
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.ValidationWebhook = &popv1beta1.ValidationWebhook{
+		ClientConfig: popv1beta1.WebhookClientConfig{URL: ptr.To("https://populator.example.com/validate")},
+	}
+	plugins, err := validation.DefaultRegistry.Build([]string{validation.PopulatorWebhookPluginName}, validation.Handle{})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+
+	testCases := []struct {
+		name    string
+		plugins []validation.Plugin
+		status  metav1.ConditionStatus
+		reason  string
+		event   string
+	}{
+		{
+			name:    "enabled",
+			plugins: plugins,
+			status:  metav1.ConditionTrue,
+			reason:  popv1beta1.VolumePopulatorReasonWebhookEnabled,
+		},
+		{
+			name:   "not enabled",
+			status: metav1.ConditionFalse,
+			reason: popv1beta1.VolumePopulatorReasonWebhookNotEnabled,
+			event:  "Warning ValidationWebhookNotEnabled The validation webhook is not called",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, lister := makeFakeClient(populator)
+			recorder := record.NewFakeRecorder(10)
+			ctrl := &populatorController{
+				dynClient:     client,
+				eventRecorder: recorder,
+				popLister:     lister,
+				metrics:       new(FakeMetricsManager),
+				plugins:       tc.plugins,
+			}
+			if err := ctrl.syncPopulatorByKey(populator.Name); err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			unstPopulator, err := client.Resource(PopulatorResource).Get(context.TODO(), populator.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			updated, err := validation.ConvertPopulator(unstPopulator)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			condition := meta.FindStatusCondition(updated.Status.Conditions, popv1beta1.VolumePopulatorValidationWebhookEnabled)
+			if condition == nil {
+				t.Fatalf("expected the %s condition", popv1beta1.VolumePopulatorValidationWebhookEnabled)
+			}
+			if condition.Status != tc.status || condition.Reason != tc.reason {
+				t.Errorf(`expected "%v/%v" to equal "%v/%v"`, condition.Status, condition.Reason, tc.status, tc.reason)
+			}
+			select {
+			case event := <-recorder.Events:
+				if tc.event == "" {
+					t.Errorf(`unexpected event "%s"`, event)
+				} else if !strings.HasPrefix(event, tc.event) {
+					t.Errorf(`expected "%s" to equal "%s"`, event, tc.event)
+				}
+			default:
+				if tc.event != "" {
+					t.Errorf(`expected event "%s"`, tc.event)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncPopulatorSuspended(t *testing.T) {
+	suspended := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	suspended.Suspended = true
+	suspended.SuspendReason = "INC-42: populator corrupts images"
+	client, lister := makeFakeClient(suspended)
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(makeSourcePVC("pending", "source", v1.ClaimPending)), 0)
+	pvcInformer := factory.Core().V1().PersistentVolumeClaims()
+	pvcInformer.Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, pvcInformer.Informer().HasSynced)
+	ctrl := &populatorController{
+		dynClient:     client,
+		eventRecorder: record.NewFakeRecorder(10),
+		popLister:     lister,
+		pvcLister:     pvcInformer.Lister(),
+		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "pvc"),
+		metrics:       new(FakeMetricsManager),
+	}
+
+	if err := ctrl.syncPopulatorByKey("valid"); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	// Pending PVCs are flagged straight away.
+	if ctrl.queue.Len() != 1 {
+		t.Errorf(`expected "%v" to equal "%v"`, ctrl.queue.Len(), 1)
+	}
+	unstPopulator, err := client.Resource(PopulatorResource).Get(context.TODO(), "valid", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	populator, err := validation.ConvertPopulator(unstPopulator)
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	condition := meta.FindStatusCondition(populator.Status.Conditions, popv1beta1.VolumePopulatorSuspended)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Message != suspended.SuspendReason {
+		t.Errorf(`expected a Suspended condition with message "%s", got "%v"`, suspended.SuspendReason, condition)
+	}
+
+	result, err := ctrl.validator().ValidatePVC(makeSourcePVC("pending", "source", v1.ClaimPending))
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if result.Valid || result.Reason != validation.ReasonPopulatorSuspended {
+		t.Errorf(`expected "%v" to equal "%v"`, result.Reason, validation.ReasonPopulatorSuspended)
+	}
+	if !strings.Contains(result.Message, suspended.SuspendReason) {
+		t.Errorf(`expected "%s" to contain "%s"`, result.Message, suspended.SuspendReason)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// podPVCIndex indexes Pods by the namespace/name of the PVCs they use.
+const podPVCIndex = "pvc"
+
+// WithWorkloadEvents makes the controller copy the warning events of PVCs to
+// the Pods and StatefulSets that use them. The informers must not be
+// started yet.
+func WithWorkloadEvents(podInformer coreinformers.PodInformer, statefulSetInformer appsinformers.StatefulSetInformer) Option {
+	return func(ctrl *populatorController) {
+		err := podInformer.Informer().AddIndexers(cache.Indexers{podPVCIndex: podPVCIndexFunc})
+		if err != nil {
+			klog.Fatalf("Failed to add pod index: %v", err)
+		}
+		ctrl.podIndexer = podInformer.Informer().GetIndexer()
+		ctrl.podListerSynced = podInformer.Informer().HasSynced
+		ctrl.ssLister = statefulSetInformer.Lister()
+		ctrl.ssListerSynced = statefulSetInformer.Informer().HasSynced
+	}
+}
+
+func podPVCIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	var keys []string
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			keys = append(keys, pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName)
+		case volume.Ephemeral != nil:
+			// Generic ephemeral volumes are named <pod>-<volume>.
+			keys = append(keys, pod.Namespace+"/"+pod.Name+"-"+volume.Name)
+		}
+	}
+	return keys, nil
+}
+
+// pvcWarning emits a warning event on a PVC and, when enabled, the same
+// event on the workloads that use the PVC.
+func (ctrl *populatorController) pvcWarning(pvc *v1.PersistentVolumeClaim, reason, message string) {
+	ctrl.eventRecorder.Event(pvc, v1.EventTypeWarning, reason, message)
+	if ctrl.podIndexer == nil {
+		return
+	}
+
+	workloads, err := ctrl.pvcWorkloads(pvc)
+	if err != nil {
+		klog.Errorf("failed to find workloads of pvc %s/%s: %v", pvc.Namespace, pvc.Name, err)
+	}
+	for _, workload := range workloads {
+		ctrl.eventRecorder.Eventf(workload, v1.EventTypeWarning, reason, "PersistentVolumeClaim %s: %s", pvc.Name, message)
+	}
+}
+
+// pvcWorkloads returns the Pod that owns an ephemeral PVC, the StatefulSet
+// that created the PVC from one of its volumeClaimTemplates and the Pending
+// Pods that use the PVC.
+func (ctrl *populatorController) pvcWorkloads(pvc *v1.PersistentVolumeClaim) ([]runtime.Object, error) {
+	var workloads []runtime.Object
+	seen := make(map[types.UID]bool)
+	add := func(obj metav1.Object) {
+		if !seen[obj.GetUID()] {
+			seen[obj.GetUID()] = true
+			workloads = append(workloads, obj.(runtime.Object))
+		}
+	}
+
+	objs, err := ctrl.podIndexer.ByIndex(podPVCIndex, pvc.Namespace+"/"+pvc.Name)
+	if err != nil {
+		return nil, err
+	}
+	owner := metav1.GetControllerOf(pvc)
+	for _, obj := range objs {
+		pod := obj.(*v1.Pod)
+		ownsPVC := owner != nil && owner.Kind == "Pod" && owner.UID == pod.UID
+		if ownsPVC || pod.Status.Phase == v1.PodPending {
+			add(pod)
+		}
+	}
+
+	statefulSet, err := ctrl.pvcStatefulSet(pvc)
+	if err != nil {
+		return workloads, err
+	}
+	if statefulSet != nil {
+		add(statefulSet)
+	}
+	return workloads, nil
+}
+
+// pvcStatefulSet finds the StatefulSet that created a PVC. StatefulSets own
+// their PVCs only with some retention policies, so PVCs are also matched by
+// the <template>-<statefulset>-<ordinal> naming convention and the
+// StatefulSet selector, whose labels are copied to the PVCs.
+func (ctrl *populatorController) pvcStatefulSet(pvc *v1.PersistentVolumeClaim) (metav1.Object, error) {
+	statefulSets, err := ctrl.ssLister.StatefulSets(pvc.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, ownerRef := range pvc.OwnerReferences {
+		if ownerRef.Kind != "StatefulSet" {
+			continue
+		}
+		for _, statefulSet := range statefulSets {
+			if statefulSet.UID == ownerRef.UID {
+				return statefulSet, nil
+			}
+		}
+	}
+	for _, statefulSet := range statefulSets {
+		selector, err := metav1.LabelSelectorAsSelector(statefulSet.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(pvc.Labels)) {
+			continue
+		}
+		for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+			prefix := fmt.Sprintf("%s-%s-", template.Name, statefulSet.Name)
+			if !strings.HasPrefix(pvc.Name, prefix) {
+				continue
+			}
+			if _, err := strconv.Atoi(strings.TrimPrefix(pvc.Name, prefix)); err == nil {
+				return statefulSet, nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// Kinds of the workloads whose PVC templates are validated, used as the
+// first part of workload queue keys.
+const (
+	statefulSetKind = "StatefulSet"
+	podKind         = "Pod"
+)
+
+// pvcTemplate is a PVC template of a workload.
+type pvcTemplate struct {
+	// description names the template in events, e.g.
+	// `volumeClaimTemplate "data"`.
+	description string
+	spec        *v1.PersistentVolumeClaimSpec
+}
+
+// templateProblem is a PVC template with a data source that would be
+// rejected.
+type templateProblem struct {
+	template string
+	reason   string
+	message  string
+}
+
+// WithWorkloadValidation makes the controller validate the data sources of
+// the volumeClaimTemplates of StatefulSets and of the ephemeral volumes of
+// Pods, before the PVCs are created. The informers must not be started yet.
+func WithWorkloadValidation(podInformer coreinformers.PodInformer, statefulSetInformer appsinformers.StatefulSetInformer) Option {
+	return func(ctrl *populatorController) {
+		ctrl.workloadQueue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "workload")
+		// Pod volumes are immutable, so a Pod is validated only once.
+		podInformer.Informer().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc: ctrl.enqueueWorkload,
+			},
+		)
+		statefulSetInformer.Informer().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc: ctrl.enqueueWorkload,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					if oldObj.(metav1.Object).GetGeneration() != newObj.(metav1.Object).GetGeneration() {
+						ctrl.enqueueWorkload(newObj)
+					}
+				},
+			},
+		)
+		ctrl.podLister = podInformer.Lister()
+		ctrl.podListerSynced = podInformer.Informer().HasSynced
+		ctrl.ssLister = statefulSetInformer.Lister()
+		ctrl.ssListerSynced = statefulSetInformer.Informer().HasSynced
+	}
+}
+
+// enqueueWorkload adds a StatefulSet or a Pod with PVC templates that have
+// a data source to the workload queue.
+func (ctrl *populatorController) enqueueWorkload(obj interface{}) {
+	var kind string
+	switch obj.(type) {
+	case *appsv1.StatefulSet:
+		kind = statefulSetKind
+	case *v1.Pod:
+		kind = podKind
+	default:
+		return
+	}
+	if !hasTemplateDataSource(obj.(runtime.Object)) {
+		return
+	}
+	objName, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("failed to get key from object: %v, %v", err, obj)
+		return
+	}
+	klog.V(5).Infof("enqueued %s %q for sync", kind, objName)
+	ctrl.workloadQueue.Add(kind + "/" + objName)
+}
+
+// workloadWorker is the main worker for workloads.
+func (ctrl *populatorController) workloadWorker() {
+	keyObj, quit := ctrl.workloadQueue.Get()
+	if quit {
+		return
+	}
+	defer ctrl.workloadQueue.Done(keyObj)
+
+	if err := ctrl.syncWorkloadByKey(keyObj.(string)); err != nil {
+		ctrl.workloadQueue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to sync workload %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		ctrl.workloadQueue.Forget(keyObj)
+	}
+}
+
+// syncWorkloadByKey validates the PVC templates of a workload and emits a
+// warning event on the workload for each template that would be rejected.
+func (ctrl *populatorController) syncWorkloadByKey(key string) error {
+	klog.V(5).Infof("syncWorkloadByKey[%s]", key)
+
+	kind, objName, found := strings.Cut(key, "/")
+	if !found {
+		klog.Errorf("invalid workload key %q", key)
+		return nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(objName)
+	if err != nil {
+		klog.Errorf("error getting namespace & name of workload %q: %v", key, err)
+		return nil
+	}
+	var obj runtime.Object
+	switch kind {
+	case statefulSetKind:
+		obj, err = ctrl.ssLister.StatefulSets(namespace).Get(name)
+	case podKind:
+		obj, err = ctrl.podLister.Pods(namespace).Get(name)
+	default:
+		klog.Errorf("invalid workload key %q", key)
+		return nil
+	}
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(5).Infof("workload %q in work queue no longer exists", key)
+			return nil
+		}
+		return err
+	}
+
+	problems, err := ctrl.validateWorkload(obj)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		ctrl.eventRecorder.Eventf(obj, v1.EventTypeWarning, problem.reason, "%s: %s", problem.template, problem.message)
+	}
+	return nil
+}
+
+// ValidateWorkload validates the data sources of the PVC templates of a
+// StatefulSet or a Pod and returns a warning for each template that would
+// be rejected.
+func (ctrl *populatorController) ValidateWorkload(obj runtime.Object) ([]string, error) {
+	problems, err := ctrl.validateWorkload(obj)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, problem := range problems {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", problem.template, problem.message))
+	}
+	return warnings, nil
+}
+
+func (ctrl *populatorController) validateWorkload(obj runtime.Object) ([]templateProblem, error) {
+	namespace := obj.(metav1.Object).GetNamespace()
+	var problems []templateProblem
+	for _, template := range workloadTemplates(obj) {
+		if template.spec.DataSourceRef == nil || ctrl.skipWhileDegraded(validation.DataSourceGroupKind(template.spec.DataSourceRef)) {
+			continue
+		}
+		result, err := ctrl.validateSpec(namespace, template.spec)
+		if err != nil {
+			return nil, err
+		}
+		for _, finding := range result.Findings {
+			if finding.Severity != validation.SeverityInfo {
+				problems = append(problems, templateProblem{
+					template: template.description,
+					reason:   finding.Reason,
+					message:  finding.Message,
+				})
+			}
+		}
+		if !result.Valid && len(result.Findings) == 0 {
+			problems = append(problems, templateProblem{
+				template: template.description,
+				reason:   result.Reason,
+				message:  result.Message,
+			})
+		}
+	}
+	return problems, nil
+}
+
+// workloadTemplates returns the volumeClaimTemplates of a StatefulSet or the
+// templates of the ephemeral volumes of a Pod.
+func workloadTemplates(obj runtime.Object) []pvcTemplate {
+	var templates []pvcTemplate
+	switch workload := obj.(type) {
+	case *appsv1.StatefulSet:
+		for i := range workload.Spec.VolumeClaimTemplates {
+			template := &workload.Spec.VolumeClaimTemplates[i]
+			templates = append(templates, pvcTemplate{
+				description: fmt.Sprintf("volumeClaimTemplate %q", template.Name),
+				spec:        &template.Spec,
+			})
+		}
+	case *v1.Pod:
+		for i := range workload.Spec.Volumes {
+			volume := &workload.Spec.Volumes[i]
+			if volume.Ephemeral == nil || volume.Ephemeral.VolumeClaimTemplate == nil {
+				continue
+			}
+			templates = append(templates, pvcTemplate{
+				description: fmt.Sprintf("ephemeral volume %q", volume.Name),
+				spec:        &volume.Ephemeral.VolumeClaimTemplate.Spec,
+			})
+		}
+	}
+	return templates
+}
+
+func hasTemplateDataSource(obj runtime.Object) bool {
+	for _, template := range workloadTemplates(obj) {
+		if template.spec.DataSourceRef != nil {
+			return true
+		}
+	}
+	return false
+}
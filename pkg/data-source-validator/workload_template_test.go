@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func claimTemplateSpec(group, kind string) v1.PersistentVolumeClaimSpec {
+	return v1.PersistentVolumeClaimSpec{
+		DataSourceRef: &v1.TypedObjectReference{
+			APIGroup: ptr.To(group),
+			Kind:     kind,
+			Name:     "source",
+		},
+	}
+}
+
+func TestValidateWorkload(t *testing.T) {
+	ctrl := &populatorController{
+		popLister: makeFakeLister(makePopulator("populator", "valid.storage.k8s.io", "Valid")),
+		metrics:   &FakeMetricsManager{},
+	}
+
+	testCases := []struct {
+		name     string
+		workload runtime.Object
+		expected []string
+	}{
+		{
+			name: "StatefulSet with valid and invalid templates",
+			workload: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+						{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+						{ObjectMeta: metav1.ObjectMeta{Name: "valid"}, Spec: claimTemplateSpec("valid.storage.k8s.io", "Valid")},
+						{ObjectMeta: metav1.ObjectMeta{Name: "invalid"}, Spec: claimTemplateSpec("invalid.storage.k8s.io", "Invalid")},
+					},
+				},
+			},
+			expected: []string{
+				`volumeClaimTemplate "invalid": The datasource for this PVC does not match any registered VolumePopulator`,
+			},
+		},
+		{
+			name: "Pod with invalid ephemeral volume",
+			workload: &v1.Pod{
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						claimVolume("data"),
+						{
+							Name: "scratch",
+							VolumeSource: v1.VolumeSource{
+								Ephemeral: &v1.EphemeralVolumeSource{
+									VolumeClaimTemplate: &v1.PersistentVolumeClaimTemplate{
+										Spec: claimTemplateSpec("invalid.storage.k8s.io", "Invalid"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				`ephemeral volume "scratch": The datasource for this PVC does not match any registered VolumePopulator`,
+			},
+		},
+		{
+			name: "Pod with snapshot ephemeral volume",
+			workload: &v1.Pod{
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						{
+							Name: "restored",
+							VolumeSource: v1.VolumeSource{
+								Ephemeral: &v1.EphemeralVolumeSource{
+									VolumeClaimTemplate: &v1.PersistentVolumeClaimTemplate{
+										Spec: claimTemplateSpec("snapshot.storage.k8s.io", "VolumeSnapshot"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings, err := ctrl.ValidateWorkload(tc.workload)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if !reflect.DeepEqual(warnings, tc.expected) {
+				t.Errorf(`expected "%v" to equal "%v"`, warnings, tc.expected)
+			}
+		})
+	}
+}
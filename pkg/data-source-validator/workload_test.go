@@ -0,0 +1,162 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data_source_validator
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+func makePod(name string, phase v1.PodPhase, volumes ...v1.Volume) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID("uid-" + name),
+		},
+		Spec: v1.PodSpec{
+			Volumes: volumes,
+		},
+		Status: v1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func claimVolume(claimName string) v1.Volume {
+	return v1.Volume{
+		Name: "data",
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+		},
+	}
+}
+
+func TestPVCWorkloads(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			UID:       "uid-web",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+	ephemeralPod := makePod("scratch-pod", v1.PodRunning, v1.Volume{
+		Name: "scratch",
+		VolumeSource: v1.VolumeSource{
+			Ephemeral: &v1.EphemeralVolumeSource{},
+		},
+	})
+	client := fake.NewSimpleClientset(
+		statefulSet,
+		ephemeralPod,
+		makePod("web-0", v1.PodPending, claimVolume("data-web-0")),
+		makePod("reader", v1.PodRunning, claimVolume("data-web-0")),
+	)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	ctrl := &populatorController{}
+	WithWorkloadEvents(factory.Core().V1().Pods(), factory.Apps().V1().StatefulSets())(ctrl)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	cache.WaitForCacheSync(stopCh, ctrl.podListerSynced, ctrl.ssListerSynced)
+
+	testCases := []struct {
+		name     string
+		pvc      *v1.PersistentVolumeClaim
+		expected []string
+	}{
+		{
+			name: "StatefulSet claim",
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "data-web-0",
+					Namespace: "default",
+					Labels:    map[string]string{"app": "web"},
+				},
+			},
+			expected: []string{"web", "web-0"},
+		},
+		{
+			name: "Claim with StatefulSet name but other labels",
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "data-web-1",
+					Namespace: "default",
+				},
+			},
+		},
+		{
+			name: "Claim owned by StatefulSet",
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "data-web-2",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "StatefulSet", Name: "web", UID: "uid-web"},
+					},
+				},
+			},
+			expected: []string{"web"},
+		},
+		{
+			name: "Ephemeral claim",
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "scratch-pod-scratch",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "Pod", Name: "scratch-pod", UID: "uid-scratch-pod", Controller: ptr.To(true)},
+					},
+				},
+			},
+			expected: []string{"scratch-pod"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			workloads, err := ctrl.pvcWorkloads(tc.pvc)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			var names []string
+			for _, workload := range workloads {
+				names = append(names, workload.(metav1.Object).GetName())
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf(`expected "%v" to equal "%v"`, names, tc.expected)
+			}
+		})
+	}
+}
@@ -18,20 +18,26 @@ package metrics
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	k8smetrics "k8s.io/component-base/metrics"
 )
 
 const (
-	subSystem   = "volume_data_source_validator"
-	labelResult = "result"
+	subSystem       = "volume_data_source_validator"
+	labelResult     = "result"
+	labelSourceKind = "source_kind"
+	labelPlugin     = "plugin"
+	labelSeverity   = "severity"
+	labelNamespace  = "namespace"
 
 	DataSourceEmptyResultName     = "empty"
 	DataSourcePVCResultName       = "pvc"
 	DataSourceSnapshotResultName  = "snapshot"
 	DataSourcePopulatorResultName = "populator"
 	DataSourceInvalidResultName   = "invalid"
+	DataSourceNotServedResultName = "not_served"
 	DataSourceErrorResultName     = "error"
 )
 
@@ -46,6 +52,30 @@ type MetricsManager interface {
 	// result - the result of the validation operation.
 	IncrementCount(result string)
 
+	// SetPopulatorConflicts records the number of VolumePopulators whose
+	// source kind is also registered by another VolumePopulator.
+	SetPopulatorConflicts(count int)
+
+	// SetPopulatorCRDMissing records whether the VolumePopulator CRD is
+	// missing, which makes the controller run degraded.
+	SetPopulatorCRDMissing(missing bool)
+
+	// RecordPopulationDuration records the time it took to bind a PVC
+	// populated from the given source kind, measured from its creation.
+	RecordPopulationDuration(sourceKind string, duration time.Duration)
+
+	// SetPopulationQuotaUsage records the number of Pending PVCs of a
+	// namespace that use a source kind with a population quota, and the
+	// quota. The series are removed when no PVC is Pending.
+	SetPopulationQuotaUsage(namespace, sourceKind string, pending, limit int)
+
+	// IncrementPluginFinding records a finding of a validator plugin.
+	IncrementPluginFinding(plugin, severity string)
+
+	// RecordPluginCall records the duration of a call to an out-of-process
+	// validator plugin, and whether it failed.
+	RecordPluginCall(plugin string, duration time.Duration, err error)
+
 	// GetRegistry() returns the metrics.KubeRegistry used by this metrics manager.
 	GetRegistry() k8smetrics.KubeRegistry
 }
@@ -56,6 +86,32 @@ type operationMetricsManager struct {
 
 	// opResultMetrics is a COunter metrics for operation results
 	opResultMetrics *k8smetrics.CounterVec
+
+	// populatorConflicts is a Gauge metric for conflicting VolumePopulators
+	populatorConflicts *k8smetrics.Gauge
+
+	// populatorCRDMissing is a Gauge metric set while the VolumePopulator
+	// CRD is not installed
+	populatorCRDMissing *k8smetrics.Gauge
+
+	// populationDuration is a Histogram metric for PVC population times
+	populationDuration *k8smetrics.HistogramVec
+
+	// populationQuotaUsage is a Gauge metric for Pending PVCs of source
+	// kinds with a population quota
+	populationQuotaUsage *k8smetrics.GaugeVec
+
+	// populationQuotaLimit is a Gauge metric for population quotas
+	populationQuotaLimit *k8smetrics.GaugeVec
+
+	// pluginFindings is a Counter metric for findings of validator plugins
+	pluginFindings *k8smetrics.CounterVec
+
+	// pluginCallDuration is a Histogram metric for calls to validator plugins
+	pluginCallDuration *k8smetrics.HistogramVec
+
+	// pluginCallErrors is a Counter metric for failed calls to validator plugins
+	pluginCallErrors *k8smetrics.CounterVec
 }
 
 // NewMetricsManager creates a new MetricsManager instance
@@ -70,6 +126,49 @@ func (opMgr *operationMetricsManager) IncrementCount(result string) {
 	opMgr.opResultMetrics.WithLabelValues(result).Inc()
 }
 
+// SetPopulatorConflicts records the number of conflicting VolumePopulators
+func (opMgr *operationMetricsManager) SetPopulatorConflicts(count int) {
+	opMgr.populatorConflicts.Set(float64(count))
+}
+
+// SetPopulatorCRDMissing records whether the VolumePopulator CRD is missing
+func (opMgr *operationMetricsManager) SetPopulatorCRDMissing(missing bool) {
+	if missing {
+		opMgr.populatorCRDMissing.Set(1)
+	} else {
+		opMgr.populatorCRDMissing.Set(0)
+	}
+}
+
+// RecordPopulationDuration records the time it took to populate a PVC
+func (opMgr *operationMetricsManager) RecordPopulationDuration(sourceKind string, duration time.Duration) {
+	opMgr.populationDuration.WithLabelValues(sourceKind).Observe(duration.Seconds())
+}
+
+// SetPopulationQuotaUsage records the usage of a population quota
+func (opMgr *operationMetricsManager) SetPopulationQuotaUsage(namespace, sourceKind string, pending, limit int) {
+	if pending == 0 {
+		opMgr.populationQuotaUsage.DeleteLabelValues(namespace, sourceKind)
+		opMgr.populationQuotaLimit.DeleteLabelValues(namespace, sourceKind)
+		return
+	}
+	opMgr.populationQuotaUsage.WithLabelValues(namespace, sourceKind).Set(float64(pending))
+	opMgr.populationQuotaLimit.WithLabelValues(namespace, sourceKind).Set(float64(limit))
+}
+
+// IncrementPluginFinding records a finding of a validator plugin
+func (opMgr *operationMetricsManager) IncrementPluginFinding(plugin, severity string) {
+	opMgr.pluginFindings.WithLabelValues(plugin, severity).Inc()
+}
+
+// RecordPluginCall records a call to an out-of-process validator plugin
+func (opMgr *operationMetricsManager) RecordPluginCall(plugin string, duration time.Duration, err error) {
+	opMgr.pluginCallDuration.WithLabelValues(plugin).Observe(duration.Seconds())
+	if err != nil {
+		opMgr.pluginCallErrors.WithLabelValues(plugin).Inc()
+	}
+}
+
 func (opMgr *operationMetricsManager) init() {
 	opMgr.registry = k8smetrics.NewKubeRegistry()
 	k8smetrics.RegisterProcessStartTime(opMgr.registry.Register)
@@ -82,6 +181,80 @@ func (opMgr *operationMetricsManager) init() {
 		[]string{labelResult},
 	)
 	opMgr.registry.MustRegister(opMgr.opResultMetrics)
+	opMgr.populatorConflicts = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Subsystem: subSystem,
+			Name:      "populator_conflicts",
+			Help:      "Number of VolumePopulators whose source kind is registered more than once",
+		},
+	)
+	opMgr.registry.MustRegister(opMgr.populatorConflicts)
+	opMgr.populatorCRDMissing = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Subsystem: subSystem,
+			Name:      "populator_crd_missing",
+			Help:      "1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated",
+		},
+	)
+	opMgr.registry.MustRegister(opMgr.populatorCRDMissing)
+	opMgr.populationDuration = k8smetrics.NewHistogramVec(
+		&k8smetrics.HistogramOpts{
+			Subsystem: subSystem,
+			Name:      "population_duration_seconds",
+			Help:      "Time from creation to binding of PVCs with a populator data source, by source kind",
+			// 1s to about 4.5h
+			Buckets: k8smetrics.ExponentialBuckets(1, 2, 15),
+		},
+		[]string{labelSourceKind},
+	)
+	opMgr.registry.MustRegister(opMgr.populationDuration)
+	opMgr.populationQuotaUsage = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Subsystem: subSystem,
+			Name:      "population_quota_pending",
+			Help:      "Number of Pending PVCs using a source kind with a population quota, by namespace and source kind",
+		},
+		[]string{labelNamespace, labelSourceKind},
+	)
+	opMgr.registry.MustRegister(opMgr.populationQuotaUsage)
+	opMgr.populationQuotaLimit = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Subsystem: subSystem,
+			Name:      "population_quota_limit",
+			Help:      "Maximum number of Pending PVCs per namespace using a source kind, by namespace and source kind",
+		},
+		[]string{labelNamespace, labelSourceKind},
+	)
+	opMgr.registry.MustRegister(opMgr.populationQuotaLimit)
+	opMgr.pluginFindings = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Subsystem: subSystem,
+			Name:      "plugin_finding_count",
+			Help:      "Number of findings of validator plugins by plugin and severity",
+		},
+		[]string{labelPlugin, labelSeverity},
+	)
+	opMgr.registry.MustRegister(opMgr.pluginFindings)
+	opMgr.pluginCallDuration = k8smetrics.NewHistogramVec(
+		&k8smetrics.HistogramOpts{
+			Subsystem: subSystem,
+			Name:      "plugin_call_duration_seconds",
+			Help:      "Duration of calls to out-of-process validator plugins, by plugin",
+			// 1ms to about 8s
+			Buckets: k8smetrics.ExponentialBuckets(0.001, 2, 14),
+		},
+		[]string{labelPlugin},
+	)
+	opMgr.registry.MustRegister(opMgr.pluginCallDuration)
+	opMgr.pluginCallErrors = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Subsystem: subSystem,
+			Name:      "plugin_call_error_count",
+			Help:      "Number of failed calls to out-of-process validator plugins, by plugin",
+		},
+		[]string{labelPlugin},
+	)
+	opMgr.registry.MustRegister(opMgr.pluginCallErrors)
 }
 
 func (opMgr *operationMetricsManager) PrepareMetricsPath(mux *http.ServeMux, pattern string, logger promhttp.Logger) error {
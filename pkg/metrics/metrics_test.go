@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -28,6 +29,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	cmg "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
@@ -90,6 +92,209 @@ func TestIncrementCount(t *testing.T) {
 # TYPE volume_data_source_validator_volume_data_source_validator_operation_count counter
 volume_data_source_validator_operation_count{result="test_result_1"} 1
 volume_data_source_validator_operation_count{result="test_result_2"} 2
+# HELP volume_data_source_validator_populator_conflicts [ALPHA] Number of VolumePopulators whose source kind is registered more than once
+# TYPE volume_data_source_validator_populator_conflicts gauge
+volume_data_source_validator_populator_conflicts 0
+# HELP volume_data_source_validator_populator_crd_missing [ALPHA] 1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated
+# TYPE volume_data_source_validator_populator_crd_missing gauge
+volume_data_source_validator_populator_crd_missing 0
+`
+
+	if err := verifyMetric(expected, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+}
+
+func TestSetPopulatorConflicts(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+	mgr.SetPopulatorConflicts(3)
+	mgr.SetPopulatorConflicts(2)
+
+	expected :=
+		`# HELP process_start_time_seconds [ALPHA] Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 0
+# HELP volume_data_source_validator_populator_conflicts [ALPHA] Number of VolumePopulators whose source kind is registered more than once
+# TYPE volume_data_source_validator_populator_conflicts gauge
+volume_data_source_validator_populator_conflicts 2
+# HELP volume_data_source_validator_populator_crd_missing [ALPHA] 1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated
+# TYPE volume_data_source_validator_populator_crd_missing gauge
+volume_data_source_validator_populator_crd_missing 0
+`
+
+	if err := verifyMetric(expected, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+}
+
+func TestSetPopulatorCRDMissing(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+	mgr.SetPopulatorCRDMissing(true)
+
+	expected :=
+		`# HELP process_start_time_seconds [ALPHA] Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 0
+# HELP volume_data_source_validator_populator_conflicts [ALPHA] Number of VolumePopulators whose source kind is registered more than once
+# TYPE volume_data_source_validator_populator_conflicts gauge
+volume_data_source_validator_populator_conflicts 0
+# HELP volume_data_source_validator_populator_crd_missing [ALPHA] 1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated
+# TYPE volume_data_source_validator_populator_crd_missing gauge
+volume_data_source_validator_populator_crd_missing 1
+`
+
+	if err := verifyMetric(expected, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+}
+
+func TestRecordPopulationDuration(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+	mgr.RecordPopulationDuration("Valid.valid.storage.k8s.io", 3*time.Second)
+	mgr.RecordPopulationDuration("Valid.valid.storage.k8s.io", 200*time.Second)
+
+	expected :=
+		`# HELP process_start_time_seconds [ALPHA] Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 0
+# HELP volume_data_source_validator_populator_conflicts [ALPHA] Number of VolumePopulators whose source kind is registered more than once
+# TYPE volume_data_source_validator_populator_conflicts gauge
+volume_data_source_validator_populator_conflicts 0
+# HELP volume_data_source_validator_populator_crd_missing [ALPHA] 1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated
+# TYPE volume_data_source_validator_populator_crd_missing gauge
+volume_data_source_validator_populator_crd_missing 0
+# HELP volume_data_source_validator_population_duration_seconds [ALPHA] Time from creation to binding of PVCs with a populator data source, by source kind
+# TYPE volume_data_source_validator_population_duration_seconds histogram
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="1"} 0
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="2"} 0
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="4"} 1
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="8"} 1
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="16"} 1
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="32"} 1
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="64"} 1
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="128"} 1
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="256"} 2
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="512"} 2
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="1024"} 2
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="2048"} 2
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="4096"} 2
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="8192"} 2
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="16384"} 2
+volume_data_source_validator_population_duration_seconds_bucket{source_kind="Valid.valid.storage.k8s.io",le="+Inf"} 2
+volume_data_source_validator_population_duration_seconds_sum{source_kind="Valid.valid.storage.k8s.io"} 203
+volume_data_source_validator_population_duration_seconds_count{source_kind="Valid.valid.storage.k8s.io"} 2
+`
+
+	if err := verifyMetric(expected, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+}
+
+func TestSetPopulationQuotaUsage(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+	mgr.SetPopulationQuotaUsage("tenant-a", "Valid.valid.storage.k8s.io", 3, 5)
+	mgr.SetPopulationQuotaUsage("tenant-b", "Valid.valid.storage.k8s.io", 1, 5)
+	mgr.SetPopulationQuotaUsage("tenant-b", "Valid.valid.storage.k8s.io", 0, 5)
+
+	expected :=
+		`# HELP process_start_time_seconds [ALPHA] Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 0
+# HELP volume_data_source_validator_populator_conflicts [ALPHA] Number of VolumePopulators whose source kind is registered more than once
+# TYPE volume_data_source_validator_populator_conflicts gauge
+volume_data_source_validator_populator_conflicts 0
+# HELP volume_data_source_validator_populator_crd_missing [ALPHA] 1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated
+# TYPE volume_data_source_validator_populator_crd_missing gauge
+volume_data_source_validator_populator_crd_missing 0
+# HELP volume_data_source_validator_population_quota_limit [ALPHA] Maximum number of Pending PVCs per namespace using a source kind, by namespace and source kind
+# TYPE volume_data_source_validator_population_quota_limit gauge
+volume_data_source_validator_population_quota_limit{namespace="tenant-a",source_kind="Valid.valid.storage.k8s.io"} 5
+# HELP volume_data_source_validator_population_quota_pending [ALPHA] Number of Pending PVCs using a source kind with a population quota, by namespace and source kind
+# TYPE volume_data_source_validator_population_quota_pending gauge
+volume_data_source_validator_population_quota_pending{namespace="tenant-a",source_kind="Valid.valid.storage.k8s.io"} 3
+`
+
+	if err := verifyMetric(expected, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+}
+
+func TestIncrementPluginFinding(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+	mgr.IncrementPluginFinding("SourceExists", "Warning")
+	mgr.IncrementPluginFinding("SourceExists", "Warning")
+	mgr.IncrementPluginFinding("Policy", "Error")
+
+	expected :=
+		`# HELP process_start_time_seconds [ALPHA] Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 0
+# HELP volume_data_source_validator_populator_conflicts [ALPHA] Number of VolumePopulators whose source kind is registered more than once
+# TYPE volume_data_source_validator_populator_conflicts gauge
+volume_data_source_validator_populator_conflicts 0
+# HELP volume_data_source_validator_populator_crd_missing [ALPHA] 1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated
+# TYPE volume_data_source_validator_populator_crd_missing gauge
+volume_data_source_validator_populator_crd_missing 0
+# HELP volume_data_source_validator_plugin_finding_count [ALPHA] Number of findings of validator plugins by plugin and severity
+# TYPE volume_data_source_validator_plugin_finding_count counter
+volume_data_source_validator_plugin_finding_count{plugin="Policy",severity="Error"} 1
+volume_data_source_validator_plugin_finding_count{plugin="SourceExists",severity="Warning"} 2
+`
+
+	if err := verifyMetric(expected, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+}
+
+func TestRecordPluginCall(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+	mgr.RecordPluginCall("policy", 3*time.Millisecond, nil)
+	mgr.RecordPluginCall("policy", 3*time.Second, errors.New("deadline exceeded"))
+
+	expected :=
+		`# HELP process_start_time_seconds [ALPHA] Start time of the process since unix epoch in seconds.
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 0
+# HELP volume_data_source_validator_populator_conflicts [ALPHA] Number of VolumePopulators whose source kind is registered more than once
+# TYPE volume_data_source_validator_populator_conflicts gauge
+volume_data_source_validator_populator_conflicts 0
+# HELP volume_data_source_validator_populator_crd_missing [ALPHA] 1 while the VolumePopulator CRD is not installed and only PersistentVolumeClaim and VolumeSnapshot data sources are validated
+# TYPE volume_data_source_validator_populator_crd_missing gauge
+volume_data_source_validator_populator_crd_missing 0
+# HELP volume_data_source_validator_plugin_call_duration_seconds [ALPHA] Duration of calls to out-of-process validator plugins, by plugin
+# TYPE volume_data_source_validator_plugin_call_duration_seconds histogram
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.001"} 0
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.002"} 0
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.004"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.008"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.016"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.032"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.064"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.128"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.256"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="0.512"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="1.024"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="2.048"} 1
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="4.096"} 2
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="8.192"} 2
+volume_data_source_validator_plugin_call_duration_seconds_bucket{plugin="policy",le="+Inf"} 2
+volume_data_source_validator_plugin_call_duration_seconds_sum{plugin="policy"} 3.003
+volume_data_source_validator_plugin_call_duration_seconds_count{plugin="policy"} 2
+# HELP volume_data_source_validator_plugin_call_error_count [ALPHA] Number of failed calls to out-of-process validator plugins, by plugin
+# TYPE volume_data_source_validator_plugin_call_error_count counter
+volume_data_source_validator_plugin_call_error_count{plugin="policy"} 1
 `
 
 	if err := verifyMetric(expected, srvAddr); err != nil {
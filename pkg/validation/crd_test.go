@@ -0,0 +1,270 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/install"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsvalidation "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/validation"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/client/config/crd"
+)
+
+// internalCRD converts an embedded CRD to the internal version, defaulted
+// like the API server does on creation.
+func internalCRD(t *testing.T, obj *unstructured.Unstructured) *apiextensions.CustomResourceDefinition {
+	scheme := runtime.NewScheme()
+	install.Install(scheme)
+	external := &apiextensionsv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, external); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	scheme.Default(external)
+	internal := &apiextensions.CustomResourceDefinition{}
+	if err := scheme.Convert(external, internal, nil); err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	return internal
+}
+
+// ruleValidator returns the validator of the x-kubernetes-validations of
+// the VolumePopulator CRD, as run by the API server.
+func ruleValidator(t *testing.T) (*cel.Validator, *structuralschema.Structural) {
+	obj, err := crd.VolumePopulators()
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	populatorCRD := internalCRD(t, obj)
+	if len(populatorCRD.Spec.Versions) != 1 {
+		t.Fatalf("expected one version, got %d", len(populatorCRD.Spec.Versions))
+	}
+	schema, err := apiextensions.GetSchemaForVersion(populatorCRD, populatorCRD.Spec.Versions[0].Name)
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	structural, err := structuralschema.NewStructural(schema.OpenAPIV3Schema)
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	validator := cel.NewValidator(structural, true, celconfig.PerCallLimit)
+	if validator == nil {
+		t.Fatalf("expected rules in the CRD")
+	}
+	return validator, structural
+}
+
+// ruleMessages returns the messages of the rules that reject a
+// VolumePopulator.
+func ruleMessages(t *testing.T, validator *cel.Validator, structural *structuralschema.Structural, populator map[string]interface{}) []string {
+	populator["apiVersion"] = "populator.storage.k8s.io/v1beta1"
+	populator["kind"] = "VolumePopulator"
+	populator["metadata"] = map[string]interface{}{"name": "populator"}
+	errs, _ := validator.Validate(context.TODO(), field.NewPath(""), structural, populator, nil, celconfig.RuntimeCELCostBudget)
+	var messages []string
+	for _, err := range errs {
+		if err.Type != field.ErrorTypeInvalid {
+			t.Fatalf("unexpected error %v", err)
+		}
+		messages = append(messages, err.Detail)
+	}
+	return messages
+}
+
+// TestCRDsValid validates the embedded CRDs like the API server does on
+// creation, which checks that the rules compile, have the right types and
+// fit the cost budget.
+func TestCRDsValid(t *testing.T) {
+	crds, err := crd.All()
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	for _, obj := range crds {
+		if errs := apiextensionsvalidation.ValidateCustomResourceDefinition(context.TODO(), internalCRD(t, obj)); len(errs) > 0 {
+			t.Errorf("CRD %s is invalid: %v", obj.GetName(), errs.ToAggregate())
+		}
+	}
+}
+
+// TestCRDSourceKindRules evaluates the x-kubernetes-validations of
+// sourceKind in the CRD.
+func TestCRDSourceKindRules(t *testing.T) {
+	validator, structural := ruleValidator(t)
+
+	testCases := []struct {
+		name     string
+		group    string
+		kind     string
+		messages []string
+	}{
+		{
+			name:  "Valid",
+			group: "valid.storage.k8s.io",
+			kind:  "Valid",
+		},
+		{
+			name:  "Core group",
+			group: "",
+			kind:  "ConfigMap",
+		},
+		{
+			name:     "Empty kind",
+			group:    "valid.storage.k8s.io",
+			messages: []string{"kind must not be empty"},
+		},
+		{
+			name:     "Lowercase kind",
+			group:    "valid.storage.k8s.io",
+			kind:     "valid",
+			messages: []string{"kind must be CamelCase, starting with an uppercase letter, or *"},
+		},
+		{
+			name:     "Kind with a dot",
+			group:    "valid.storage.k8s.io",
+			kind:     "Valid.Kind",
+			messages: []string{"kind must be CamelCase, starting with an uppercase letter, or *"},
+		},
+		{
+			name:  "Wildcard",
+			group: "valid.storage.k8s.io",
+			kind:  "*",
+		},
+		{
+			name:     "Wildcard of the core group",
+			group:    "",
+			kind:     "*",
+			messages: []string{"kind * requires a group"},
+		},
+		{
+			name:     "Wildcard prefix",
+			group:    "valid.storage.k8s.io",
+			kind:     "Valid*",
+			messages: []string{"kind must be CamelCase, starting with an uppercase letter, or *"},
+		},
+		{
+			name:     "Uppercase group",
+			group:    "Valid.storage.k8s.io",
+			kind:     "Valid",
+			messages: []string{"group must be empty or a lowercase DNS subdomain"},
+		},
+		{
+			name:     "Group with an empty label",
+			group:    "valid..k8s.io",
+			kind:     "Valid",
+			messages: []string{"group must be empty or a lowercase DNS subdomain"},
+		},
+		{
+			name:     "Group with a leading dash",
+			group:    "-valid.k8s.io",
+			kind:     "Valid",
+			messages: []string{"group must be empty or a lowercase DNS subdomain"},
+		},
+		{
+			name:     "PersistentVolumeClaim",
+			group:    "",
+			kind:     "PersistentVolumeClaim",
+			messages: []string{"PersistentVolumeClaim is handled by Kubernetes and cannot be registered"},
+		},
+		{
+			name:  "PersistentVolumeClaim of another group",
+			group: "valid.storage.k8s.io",
+			kind:  "PersistentVolumeClaim",
+		},
+		{
+			name:     "VolumeSnapshot",
+			group:    "snapshot.storage.k8s.io",
+			kind:     "VolumeSnapshot",
+			messages: []string{"VolumeSnapshot is handled by Kubernetes and cannot be registered"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			messages := ruleMessages(t, validator, structural, map[string]interface{}{
+				"sourceKind": map[string]interface{}{"group": tc.group, "kind": tc.kind},
+			})
+			if !reflect.DeepEqual(messages, tc.messages) {
+				t.Errorf(`expected "%v" to equal "%v"`, messages, tc.messages)
+			}
+		})
+	}
+}
+
+// TestCRDObjectRules evaluates the x-kubernetes-validations of the whole
+// VolumePopulator in the CRD.
+func TestCRDObjectRules(t *testing.T) {
+	validator, structural := ruleValidator(t)
+
+	testCases := []struct {
+		name     string
+		kind     string
+		fields   map[string]interface{}
+		messages []string
+	}{
+		{
+			name: "Exact kind",
+			kind: "Valid",
+		},
+		{
+			name:   "Wildcard with exclusions",
+			kind:   "*",
+			fields: map[string]interface{}{"excludedKinds": []interface{}{"Internal"}},
+		},
+		{
+			name:     "Exact kind with exclusions",
+			kind:     "Valid",
+			fields:   map[string]interface{}{"excludedKinds": []interface{}{"Internal"}},
+			messages: []string{"excludedKinds is only allowed when the kind of sourceKind is *"},
+		},
+		{
+			name:   "Suspended with a reason",
+			kind:   "Valid",
+			fields: map[string]interface{}{"suspended": true, "suspendReason": "INC-42"},
+		},
+		{
+			name:     "Reason without suspension",
+			kind:     "Valid",
+			fields:   map[string]interface{}{"suspended": false, "suspendReason": "INC-42"},
+			messages: []string{"suspendReason is only allowed while suspended"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			populator := map[string]interface{}{
+				"sourceKind": map[string]interface{}{"group": "valid.storage.k8s.io", "kind": tc.kind},
+			}
+			for field, value := range tc.fields {
+				populator[field] = value
+			}
+			messages := ruleMessages(t, validator, structural, populator)
+			if !reflect.DeepEqual(messages, tc.messages) {
+				t.Errorf(`expected "%v" to equal "%v"`, messages, tc.messages)
+			}
+		})
+	}
+}
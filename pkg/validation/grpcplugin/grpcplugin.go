@@ -0,0 +1,260 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcplugin runs validator plugins out of process, as gRPC servers
+// listening on a Unix domain socket, typically in a sidecar container.
+//
+// The service is datasourcevalidator.v1.Validator, with a single unary
+// method Validate. Messages are ValidateRequest and ValidateResponse encoded
+// as JSON, with the "json" content-subtype, so plugins do not need generated
+// code. The JSON codec is not registered globally, it is only used by the
+// connections to plugins and by servers created with ServerOption. Plugins
+// written in Go import this package and call Register:
+//
+//	server := grpc.NewServer(grpcplugin.ServerOption())
+//	grpcplugin.Register(server, myValidator)
+//	server.Serve(listener)
+package grpcplugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+const (
+	// ServiceName is the full name of the gRPC service of plugins.
+	ServiceName = "datasourcevalidator.v1.Validator"
+
+	validateMethod = "/" + ServiceName + "/Validate"
+	codecName      = "json"
+)
+
+// ReasonPluginFailed is the reason of the finding reported when a plugin
+// with the Fail policy cannot be called.
+const ReasonPluginFailed = "ValidationPluginFailed"
+
+// Verdict is the decision of a plugin.
+type Verdict string
+
+const (
+	// VerdictAllow accepts the data source. A message is reported as an
+	// Info finding.
+	VerdictAllow Verdict = "Allow"
+	// VerdictWarn accepts the data source with a Warning finding.
+	VerdictWarn Verdict = "Warn"
+	// VerdictDeny rejects the data source with an Error finding.
+	VerdictDeny Verdict = "Deny"
+)
+
+// ValidateRequest is sent to plugins.
+type ValidateRequest struct {
+	// PVC is the validated PVC. Only the namespace and the spec are set
+	// when the template of a workload is validated.
+	PVC *v1.PersistentVolumeClaim `json:"pvc"`
+	// Populator is the VolumePopulator that matched the data source, nil
+	// for PVC and VolumeSnapshot sources.
+	Populator *popv1beta1.VolumePopulator `json:"populator,omitempty"`
+}
+
+// ValidateResponse is returned by plugins.
+type ValidateResponse struct {
+	Verdict Verdict `json:"verdict"`
+	// Reason is a CamelCase code, used as event reason. It defaults to
+	// the name of the plugin.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// FailurePolicy says what happens when a plugin cannot be called, or
+// returns an invalid verdict.
+type FailurePolicy string
+
+const (
+	// FailurePolicyIgnore accepts the data source.
+	FailurePolicyIgnore FailurePolicy = "Ignore"
+	// FailurePolicyFail rejects the data source with an Error finding.
+	FailurePolicyFail FailurePolicy = "Fail"
+)
+
+// Config configures a plugin.
+type Config struct {
+	// Name identifies the plugin in events and metrics.
+	Name string
+	// Socket is the path of the Unix domain socket of the plugin.
+	Socket        string
+	Timeout       time.Duration
+	FailurePolicy FailurePolicy
+}
+
+// Metrics records calls to plugins. It is implemented by
+// metrics.MetricsManager.
+type Metrics interface {
+	RecordPluginCall(plugin string, duration time.Duration, err error)
+}
+
+type plugin struct {
+	config  Config
+	conn    *grpc.ClientConn
+	metrics Metrics
+}
+
+// New returns a validator plugin that calls a gRPC plugin. The connection is
+// established on the first call.
+func New(config Config, metrics Metrics) (validation.Plugin, error) {
+	if config.Name == "" || config.Socket == "" {
+		return nil, fmt.Errorf("a name and a socket are required")
+	}
+	if config.Timeout <= 0 {
+		return nil, fmt.Errorf("plugin %s: the timeout must be positive", config.Name)
+	}
+	if config.FailurePolicy != FailurePolicyIgnore && config.FailurePolicy != FailurePolicyFail {
+		return nil, fmt.Errorf("plugin %s: unknown failure policy %q", config.Name, config.FailurePolicy)
+	}
+	conn, err := grpc.NewClient("unix:"+config.Socket,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", config.Name, err)
+	}
+	return &plugin{config: config, conn: conn, metrics: metrics}, nil
+}
+
+func (p *plugin) Name() string {
+	return p.config.Name
+}
+
+func (p *plugin) Validate(request validation.Request) ([]validation.Finding, error) {
+	pvc := request.PVC
+	if pvc == nil {
+		pvc = &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace},
+			Spec:       *request.Spec,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+	start := time.Now()
+	response := &ValidateResponse{}
+	err := p.conn.Invoke(ctx, validateMethod, &ValidateRequest{PVC: pvc, Populator: request.Result.Populator}, response)
+	if err == nil {
+		err = checkVerdict(response.Verdict)
+	}
+	p.metrics.RecordPluginCall(p.config.Name, time.Since(start), err)
+	if err != nil {
+		if p.config.FailurePolicy == FailurePolicyIgnore {
+			klog.Warningf("Ignoring failure of validator plugin %s for pvc %s/%s: %v", p.config.Name, pvc.Namespace, pvc.Name, err)
+			return nil, nil
+		}
+		return []validation.Finding{{
+			Severity: validation.SeverityError,
+			Reason:   ReasonPluginFailed,
+			Message:  fmt.Sprintf("Validator plugin %s failed: %v", p.config.Name, err),
+		}}, nil
+	}
+
+	reason := response.Reason
+	if reason == "" {
+		reason = p.config.Name
+	}
+	switch response.Verdict {
+	case VerdictWarn:
+		return []validation.Finding{{Severity: validation.SeverityWarning, Reason: reason, Message: response.Message}}, nil
+	case VerdictDeny:
+		return []validation.Finding{{Severity: validation.SeverityError, Reason: reason, Message: response.Message}}, nil
+	default:
+		if response.Message == "" {
+			return nil, nil
+		}
+		return []validation.Finding{{Severity: validation.SeverityInfo, Reason: reason, Message: response.Message}}, nil
+	}
+}
+
+func checkVerdict(verdict Verdict) error {
+	switch verdict {
+	case VerdictAllow, VerdictWarn, VerdictDeny:
+		return nil
+	default:
+		return fmt.Errorf("invalid verdict %q", verdict)
+	}
+}
+
+// ValidatorServer is implemented by plugins.
+type ValidatorServer interface {
+	Validate(ctx context.Context, request *ValidateRequest) (*ValidateResponse, error)
+}
+
+// ServerOption makes a gRPC server encode messages as JSON, like the
+// validator does. Servers of plugins must be created with it.
+func ServerOption() grpc.ServerOption {
+	return grpc.ForceServerCodec(jsonCodec{})
+}
+
+// Register registers a plugin with a gRPC server created with ServerOption.
+func Register(server *grpc.Server, validator ValidatorServer) {
+	server.RegisterService(&serviceDesc, validator)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ValidatorServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Validate",
+		Handler:    validateHandler,
+	}},
+}
+
+func validateHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &ValidateRequest{}
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidatorServer).Validate(ctx, request)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: validateMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidatorServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, request, info, handler)
+}
+
+// jsonCodec encodes messages as JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcplugin
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"google.golang.org/grpc"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-csi/volume-data-source-validator/pkg/validation"
+)
+
+// fakeValidator returns the verdict named by the PVC, and hangs for PVCs
+// named "slow".
+type fakeValidator struct {
+	populators []string
+}
+
+func (v *fakeValidator) Validate(ctx context.Context, request *ValidateRequest) (*ValidateResponse, error) {
+	if request.Populator != nil {
+		v.populators = append(v.populators, request.Populator.Name)
+	}
+	switch request.PVC.Name {
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "":
+		return &ValidateResponse{Verdict: VerdictAllow, Message: "template in " + request.PVC.Namespace}, nil
+	default:
+		return &ValidateResponse{Verdict: Verdict(request.PVC.Name), Message: "from plugin"}, nil
+	}
+}
+
+type fakeMetrics struct {
+	calls  int
+	errors int
+}
+
+func (m *fakeMetrics) RecordPluginCall(plugin string, duration time.Duration, err error) {
+	m.calls++
+	if err != nil {
+		m.errors++
+	}
+}
+
+func startServer(t *testing.T, validator ValidatorServer) string {
+	// t.TempDir can exceed the length limit of socket paths.
+	dir, err := os.MkdirTemp("", "grpcplugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(ServerOption())
+	Register(server, validator)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return socket
+}
+
+func TestValidate(t *testing.T) {
+	validator := &fakeValidator{}
+	socket := startServer(t, validator)
+	spec := &v1.PersistentVolumeClaimSpec{}
+	populator := &popv1beta1.VolumePopulator{ObjectMeta: metav1.ObjectMeta{Name: "valid"}}
+
+	testCases := []struct {
+		name     string
+		pvcName  string
+		policy   FailurePolicy
+		findings []validation.Finding
+		errors   int
+	}{
+		{
+			name:    "Allow",
+			pvcName: "Allow",
+			policy:  FailurePolicyFail,
+			findings: []validation.Finding{
+				{Severity: validation.SeverityInfo, Reason: "policy", Message: "from plugin"},
+			},
+		},
+		{
+			name:    "Warn",
+			pvcName: "Warn",
+			policy:  FailurePolicyFail,
+			findings: []validation.Finding{
+				{Severity: validation.SeverityWarning, Reason: "policy", Message: "from plugin"},
+			},
+		},
+		{
+			name:    "Deny",
+			pvcName: "Deny",
+			policy:  FailurePolicyIgnore,
+			findings: []validation.Finding{
+				{Severity: validation.SeverityError, Reason: "policy", Message: "from plugin"},
+			},
+		},
+		{
+			name:    "Template",
+			pvcName: "",
+			policy:  FailurePolicyFail,
+			findings: []validation.Finding{
+				{Severity: validation.SeverityInfo, Reason: "policy", Message: "template in default"},
+			},
+		},
+		{
+			name:    "Invalid verdict is ignored",
+			pvcName: "Maybe",
+			policy:  FailurePolicyIgnore,
+			errors:  1,
+		},
+		{
+			name:    "Timeout is ignored",
+			pvcName: "slow",
+			policy:  FailurePolicyIgnore,
+			errors:  1,
+		},
+		{
+			name:    "Timeout fails",
+			pvcName: "slow",
+			policy:  FailurePolicyFail,
+			findings: []validation.Finding{
+				{Severity: validation.SeverityError, Reason: ReasonPluginFailed},
+			},
+			errors: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := &fakeMetrics{}
+			plugin, err := New(Config{
+				Name:          "policy",
+				Socket:        socket,
+				Timeout:       500 * time.Millisecond,
+				FailurePolicy: tc.policy,
+			}, metrics)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			request := validation.Request{
+				Namespace: "default",
+				Spec:      spec,
+				Result:    validation.Result{Valid: true, Source: validation.SourcePopulator, Populator: populator},
+			}
+			if tc.pvcName != "" {
+				request.PVC = &v1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: tc.pvcName},
+					Spec:       *spec,
+				}
+			}
+			findings, err := plugin.Validate(request)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if tc.findings != nil && len(findings) == 1 && findings[0].Reason == ReasonPluginFailed {
+				// The message contains the gRPC error.
+				findings[0].Message = ""
+			}
+			if !reflect.DeepEqual(findings, tc.findings) {
+				t.Errorf(`expected "%v" to equal "%v"`, findings, tc.findings)
+			}
+			if metrics.calls != 1 {
+				t.Errorf(`expected "%v" to equal "%v"`, metrics.calls, 1)
+			}
+			if metrics.errors != tc.errors {
+				t.Errorf(`expected "%v" to equal "%v"`, metrics.errors, tc.errors)
+			}
+		})
+	}
+
+	if len(validator.populators) != len(testCases) || validator.populators[0] != "valid" {
+		t.Errorf("expected the populator to be sent, got %v", validator.populators)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	testCases := []Config{
+		{Socket: "/plugin.sock", Timeout: time.Second, FailurePolicy: FailurePolicyFail},
+		{Name: "policy", Socket: "/plugin.sock", FailurePolicy: FailurePolicyFail},
+		{Name: "policy", Socket: "/plugin.sock", Timeout: time.Second, FailurePolicy: "Retry"},
+	}
+	for _, config := range testCases {
+		if _, err := New(config, &fakeMetrics{}); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+}
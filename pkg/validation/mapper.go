@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// DefaultMapperResetInterval is the minimum time between two resets of the
+// REST mappers that plugins use.
+const DefaultMapperResetInterval = 30 * time.Second
+
+// refreshingMapper resets a RESTMapper that caches discovery when a kind is
+// not found, as the kind may have been installed since discovery was
+// cached.
+type refreshingMapper struct {
+	meta.RESTMapper
+	resettable meta.ResettableRESTMapper
+	interval   time.Duration
+	now        func() time.Time
+
+	lock      sync.Mutex
+	lastReset time.Time
+}
+
+// NewRefreshingRESTMapper returns a RESTMapper that looks up a kind that is
+// not found again after resetting the given mapper. Resets run discovery
+// again, so they happen at most once per interval, no matter how often
+// unknown kinds are looked up. Mappers that cannot be reset, or already
+// reset themselves, are returned unchanged.
+func NewRefreshingRESTMapper(mapper meta.RESTMapper, interval time.Duration) meta.RESTMapper {
+	if _, ok := mapper.(*refreshingMapper); ok {
+		return mapper
+	}
+	resettable, ok := mapper.(meta.ResettableRESTMapper)
+	if !ok {
+		return mapper
+	}
+	return &refreshingMapper{
+		RESTMapper: mapper,
+		resettable: resettable,
+		interval:   interval,
+		now:        time.Now,
+	}
+}
+
+func (m *refreshingMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	mapping, err := m.RESTMapper.RESTMapping(gk, versions...)
+	if err == nil || !meta.IsNoMatchError(err) || !m.reset() {
+		return mapping, err
+	}
+	return m.RESTMapper.RESTMapping(gk, versions...)
+}
+
+func (m *refreshingMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	mappings, err := m.RESTMapper.RESTMappings(gk, versions...)
+	if err == nil || !meta.IsNoMatchError(err) || !m.reset() {
+		return mappings, err
+	}
+	return m.RESTMapper.RESTMappings(gk, versions...)
+}
+
+func (m *refreshingMapper) Reset() {
+	m.resettable.Reset()
+}
+
+// reset resets the mapper, unless it was reset less than an interval ago.
+func (m *refreshingMapper) reset() bool {
+	m.lock.Lock()
+	now := m.now()
+	if !m.lastReset.IsZero() && now.Sub(m.lastReset) < m.interval {
+		m.lock.Unlock()
+		return false
+	}
+	m.lastReset = now
+	m.lock.Unlock()
+
+	klog.V(4).Infof("Resetting the REST mapper to look for new kinds")
+	m.resettable.Reset()
+	return true
+}
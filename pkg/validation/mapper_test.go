@@ -0,0 +1,81 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// installingMapper installs its pending kinds when it is reset, like a
+// mapper that runs discovery again.
+type installingMapper struct {
+	*meta.DefaultRESTMapper
+	pending []schema.GroupVersionKind
+	resets  int
+}
+
+func (m *installingMapper) Reset() {
+	m.resets++
+	for _, gvk := range m.pending {
+		m.Add(gvk, meta.RESTScopeNamespace)
+	}
+	m.pending = nil
+}
+
+func TestRefreshingRESTMapper(t *testing.T) {
+	gv := schema.GroupVersion{Group: "valid.storage.k8s.io", Version: "v1"}
+	installing := &installingMapper{DefaultRESTMapper: meta.NewDefaultRESTMapper([]schema.GroupVersion{gv})}
+	mapper := NewRefreshingRESTMapper(installing, time.Minute).(*refreshingMapper)
+	now := time.Now()
+	mapper.now = func() time.Time { return now }
+
+	// An unknown kind resets the mapper once.
+	unknown := schema.GroupKind{Group: "valid.storage.k8s.io", Kind: "Unknown"}
+	if _, err := mapper.RESTMapping(unknown); !meta.IsNoMatchError(err) {
+		t.Errorf(`expected a no match error, got "%v"`, err)
+	}
+	if installing.resets != 1 {
+		t.Errorf(`expected "%v" to equal "%v"`, installing.resets, 1)
+	}
+
+	// Further lookups within the interval do not reset it.
+	installing.pending = []schema.GroupVersionKind{gv.WithKind("Valid")}
+	valid := schema.GroupKind{Group: "valid.storage.k8s.io", Kind: "Valid"}
+	if _, err := mapper.RESTMapping(valid); !meta.IsNoMatchError(err) {
+		t.Errorf(`expected a no match error, got "%v"`, err)
+	}
+	if installing.resets != 1 {
+		t.Errorf(`expected "%v" to equal "%v"`, installing.resets, 1)
+	}
+
+	// After the interval, a newly installed kind is found.
+	now = now.Add(time.Minute)
+	mapping, err := mapper.RESTMapping(valid)
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if mapping.Resource.Resource != "valids" {
+		t.Errorf(`expected "%v" to equal "%v"`, mapping.Resource.Resource, "valids")
+	}
+	if installing.resets != 2 {
+		t.Errorf(`expected "%v" to equal "%v"`, installing.resets, 2)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Severity says how serious a finding is.
+type Severity string
+
+const (
+	// SeverityInfo findings are reported as Normal events.
+	SeverityInfo Severity = "Info"
+	// SeverityWarning findings are reported as Warning events, the data
+	// source stays valid.
+	SeverityWarning Severity = "Warning"
+	// SeverityError findings make the data source invalid.
+	SeverityError Severity = "Error"
+)
+
+// Finding is something a plugin reports about a data source.
+type Finding struct {
+	// Plugin is the name of the plugin that reported the finding. It is
+	// set by the Validator.
+	Plugin   string
+	Severity Severity
+	// Reason is a CamelCase code, used as event reason.
+	Reason  string
+	Message string
+}
+
+// Request is the data source a plugin checks.
+type Request struct {
+	// Namespace of the PVC.
+	Namespace string
+	// PVC is the validated PVC, nil when the spec of a template is
+	// validated.
+	PVC *v1.PersistentVolumeClaim
+	// Spec of the PVC, with a dataSourceRef.
+	Spec *v1.PersistentVolumeClaimSpec
+	// Result of the built-in checks, which passed.
+	Result Result
+}
+
+// Plugin is an additional check of data sources. Plugins run in order after
+// the built-in checks, and only for data sources that passed them.
+type Plugin interface {
+	// Name identifies the plugin in configuration, events and metrics.
+	Name() string
+	// Validate checks a data source. An error means the check could not
+	// be done and is retried, problems with the data source are
+	// returned as findings.
+	Validate(request Request) ([]Finding, error)
+}
+
+// WithPlugins makes the validator run the given plugins, in order.
+func WithPlugins(plugins ...Plugin) Option {
+	return func(v *validator) {
+		v.plugins = append(v.plugins, plugins...)
+	}
+}
+
+// runPlugins runs the plugins of the validator. The first Error finding
+// makes the result invalid.
+func (v *validator) runPlugins(request Request) (Result, error) {
+	result := request.Result
+	for _, plugin := range v.plugins {
+		findings, err := plugin.Validate(request)
+		if err != nil {
+			return Result{}, fmt.Errorf("plugin %s: %w", plugin.Name(), err)
+		}
+		for _, finding := range findings {
+			finding.Plugin = plugin.Name()
+			result.Findings = append(result.Findings, finding)
+			if finding.Severity == SeverityError && result.Valid {
+				result.Valid = false
+				result.Reason = finding.Reason
+				result.Message = finding.Message
+			}
+		}
+	}
+	return result, nil
+}
+
+// Handle gives plugin factories access to the clients of the binary.
+type Handle struct {
+	Client        kubernetes.Interface
+	DynamicClient dynamic.Interface
+	// Mapper maps data source kinds to resources. Plugins reset it, at
+	// most once per DefaultMapperResetInterval, when a kind is not found.
+	Mapper meta.RESTMapper
+}
+
+// PluginFactory creates a plugin.
+type PluginFactory func(handle Handle) (Plugin, error)
+
+// Registry maps plugin names to their factories.
+type Registry map[string]PluginFactory
+
+// Register adds a plugin factory to the registry.
+func (r Registry) Register(name string, factory PluginFactory) error {
+	if _, ok := r[name]; ok {
+		return fmt.Errorf("plugin %q is already registered", name)
+	}
+	r[name] = factory
+	return nil
+}
+
+// Names returns the sorted names of the registered plugins.
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build creates the named plugins, in the given order.
+func (r Registry) Build(names []string, handle Handle) ([]Plugin, error) {
+	plugins := make([]Plugin, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		factory, ok := r[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown plugin %q, registered plugins are %v", name, r.Names())
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("plugin %q is enabled twice", name)
+		}
+		seen[name] = true
+		plugin, err := factory(handle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create plugin %q: %w", name, err)
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins, nil
+}
+
+// DefaultRegistry holds the built-in plugins and the plugins added with
+// Register. Binaries with their own plugins call Register from an init
+// function.
+var DefaultRegistry = Registry{
+	SourceExistsPluginName:     newSourceExistsPlugin,
+	ValidationRulesPluginName:  newValidationRulesPlugin,
+	PopulatorWebhookPluginName: newPopulatorWebhookPlugin,
+}
+
+// Register adds a plugin factory to DefaultRegistry. It panics if the name
+// is already registered.
+func Register(name string, factory PluginFactory) {
+	if err := DefaultRegistry.Register(name, factory); err != nil {
+		panic(err)
+	}
+}
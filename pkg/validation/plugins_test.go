@@ -0,0 +1,193 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+type fakePlugin struct {
+	name     string
+	findings []Finding
+	err      error
+}
+
+func (p *fakePlugin) Name() string {
+	return p.name
+}
+
+func (p *fakePlugin) Validate(Request) ([]Finding, error) {
+	return p.findings, p.err
+}
+
+func TestPlugins(t *testing.T) {
+	lister := &fakeLister{populators: []*popv1beta1.VolumePopulator{makePopulator("valid", "valid.storage.k8s.io", "Valid")}}
+	warning := &fakePlugin{name: "warn", findings: []Finding{{Severity: SeverityWarning, Reason: "Odd", Message: "odd source"}}}
+	deny := &fakePlugin{name: "deny", findings: []Finding{{Severity: SeverityError, Reason: "Forbidden", Message: "not allowed"}}}
+	broken := &fakePlugin{name: "broken", err: errors.New("failed")}
+
+	testCases := []struct {
+		name     string
+		plugins  []Plugin
+		kind     string
+		valid    bool
+		reason   string
+		findings []string
+		err      bool
+	}{
+		{
+			name:     "Warning keeps the source valid",
+			plugins:  []Plugin{warning},
+			kind:     "Valid",
+			valid:    true,
+			findings: []string{"warn/Odd"},
+		},
+		{
+			name:     "Error makes the source invalid",
+			plugins:  []Plugin{warning, deny},
+			kind:     "Valid",
+			reason:   "Forbidden",
+			findings: []string{"warn/Odd", "deny/Forbidden"},
+		},
+		{
+			name:    "Plugins do not run for invalid sources",
+			plugins: []Plugin{warning},
+			kind:    "Invalid",
+			reason:  ReasonUnrecognizedDataSourceKind,
+		},
+		{
+			name:    "Plugin error",
+			plugins: []Plugin{broken},
+			kind:    "Valid",
+			err:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := New(lister, WithPlugins(tc.plugins...)).ValidateSpec("default", makeSpec("valid.storage.k8s.io", tc.kind))
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
+			}
+			if result.Reason != tc.reason {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Reason, tc.reason)
+			}
+			var findings []string
+			for _, finding := range result.Findings {
+				findings = append(findings, finding.Plugin+"/"+finding.Reason)
+			}
+			if !reflect.DeepEqual(findings, tc.findings) {
+				t.Errorf(`expected "%v" to equal "%v"`, findings, tc.findings)
+			}
+		})
+	}
+}
+
+func TestRegistryBuild(t *testing.T) {
+	registry := Registry{}
+	for _, name := range []string{"a", "b"} {
+		name := name
+		if err := registry.Register(name, func(Handle) (Plugin, error) { return &fakePlugin{name: name}, nil }); err != nil {
+			t.Fatalf(`expected nil error, got "%v"`, err)
+		}
+	}
+	if err := registry.Register("a", nil); err == nil {
+		t.Errorf("expected error registering a plugin twice")
+	}
+
+	plugins, err := registry.Build([]string{"b", "a"}, Handle{})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if len(plugins) != 2 || plugins[0].Name() != "b" || plugins[1].Name() != "a" {
+		t.Errorf("expected plugins b, a, got %v", plugins)
+	}
+	if _, err := registry.Build([]string{"c"}, Handle{}); err == nil {
+		t.Errorf("expected error for unknown plugin")
+	}
+	if _, err := registry.Build([]string{"a", "a"}, Handle{}); err == nil {
+		t.Errorf("expected error for duplicate plugin")
+	}
+}
+
+func TestSourceExistsPlugin(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "valid.storage.k8s.io", Version: "v1", Resource: "valids"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvr.GroupVersion()})
+	mapper.Add(gvr.GroupVersion().WithKind("Valid"), meta.RESTScopeNamespace)
+	source := &unstructured.Unstructured{}
+	source.SetAPIVersion("valid.storage.k8s.io/v1")
+	source.SetKind("Valid")
+	source.SetNamespace("default")
+	source.SetName("source")
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "ValidList"}, source)
+
+	plugin, err := DefaultRegistry[SourceExistsPluginName](Handle{DynamicClient: client, Mapper: mapper})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+
+	findings, err := plugin.Validate(Request{Namespace: "default", Spec: makeSpec("valid.storage.k8s.io", "Valid")})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+
+	findings, err = plugin.Validate(Request{Namespace: "other", Spec: makeSpec("valid.storage.k8s.io", "Valid")})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if len(findings) != 1 || findings[0].Reason != "DataSourceNotFound" || findings[0].Severity != SeverityWarning {
+		t.Errorf("unexpected findings %v", findings)
+	}
+
+	// A kind that is not served is reported, not skipped.
+	installing := &installingMapper{DefaultRESTMapper: mapper}
+	plugin, err = DefaultRegistry[SourceExistsPluginName](Handle{DynamicClient: client, Mapper: installing})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	findings, err = plugin.Validate(Request{Namespace: "default", Spec: makeSpec("unknown.storage.k8s.io", "Unknown")})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if len(findings) != 1 || findings[0].Reason != ReasonDataSourceKindNotServed || findings[0].Severity != SeverityWarning {
+		t.Errorf("unexpected findings %v", findings)
+	}
+	if installing.resets != 1 {
+		t.Errorf(`expected "%v" to equal "%v"`, installing.resets, 1)
+	}
+}
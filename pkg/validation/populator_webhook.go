@@ -0,0 +1,309 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+)
+
+// PopulatorWebhookPluginName is the name of the plugin that calls the
+// validationWebhook of VolumePopulators.
+const PopulatorWebhookPluginName = "PopulatorWebhook"
+
+// Reasons of the findings of the PopulatorWebhook plugin.
+const (
+	// ReasonPopulatorWebhookDenied is used when a webhook rejects a PVC
+	// without a reason.
+	ReasonPopulatorWebhookDenied = "PopulatorWebhookDenied"
+	// ReasonPopulatorWebhookWarning is used for the warnings of a webhook.
+	ReasonPopulatorWebhookWarning = "PopulatorWebhookWarning"
+	// ReasonPopulatorWebhookFailed means a webhook could not be called or
+	// returned an invalid response.
+	ReasonPopulatorWebhookFailed = "PopulatorWebhookFailed"
+)
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	// webhookCacheTTL is how long the verdict of a webhook for a PVC is
+	// reused.
+	webhookCacheTTL = 5 * time.Minute
+	// maxWebhookResponseSize bounds the response read from a webhook.
+	maxWebhookResponseSize = 1 << 20
+)
+
+type webhookCacheEntry struct {
+	findings []Finding
+	expires  time.Time
+}
+
+// webhookClientEntry is the HTTP client of the webhook of a populator, for
+// the populator UID and the CA bundle it trusts.
+type webhookClientEntry struct {
+	uid      types.UID
+	caBundle []byte
+	client   *http.Client
+}
+
+type populatorWebhookPlugin struct {
+	// now is replaced in tests.
+	now func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]webhookCacheEntry
+	// clients reuse connections to webhooks, by populator name.
+	clients map[string]webhookClientEntry
+}
+
+func newPopulatorWebhookPlugin(Handle) (Plugin, error) {
+	return &populatorWebhookPlugin{
+		now:     time.Now,
+		cache:   make(map[string]webhookCacheEntry),
+		clients: make(map[string]webhookClientEntry),
+	}, nil
+}
+
+func (p *populatorWebhookPlugin) Name() string {
+	return PopulatorWebhookPluginName
+}
+
+func (p *populatorWebhookPlugin) Validate(request Request) ([]Finding, error) {
+	populator := request.Result.Populator
+	if populator == nil || populator.ValidationWebhook == nil {
+		return nil, nil
+	}
+
+	// Only PVCs are cached, templates have no UID. Any change to the
+	// populator or the PVC calls the webhook again.
+	var key string
+	if request.PVC != nil {
+		key = fmt.Sprintf("%s/%s/%s/%s", populator.UID, populator.ResourceVersion, request.PVC.UID, request.PVC.ResourceVersion)
+		if findings, ok := p.cached(key); ok {
+			return findings, nil
+		}
+	}
+
+	findings, err := p.call(populator, request)
+	if err != nil {
+		klog.Warningf("Validation webhook of populator %q failed: %v", populator.Name, err)
+		return []Finding{{
+			Severity: SeverityWarning,
+			Reason:   ReasonPopulatorWebhookFailed,
+			Message:  fmt.Sprintf("The validation webhook of VolumePopulator %s failed: %v", populator.Name, err),
+		}}, nil
+	}
+	if key != "" {
+		p.store(key, findings)
+	}
+	return findings, nil
+}
+
+func (p *populatorWebhookPlugin) cached(key string) ([]Finding, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	entry, ok := p.cache[key]
+	if !ok || p.now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.findings, true
+}
+
+func (p *populatorWebhookPlugin) store(key string, findings []Finding) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.now()
+	for k, entry := range p.cache {
+		if now.After(entry.expires) {
+			delete(p.cache, k)
+		}
+	}
+	p.cache[key] = webhookCacheEntry{findings: findings, expires: now.Add(webhookCacheTTL)}
+}
+
+// call sends a DataSourceReview to the webhook of a populator and turns the
+// response into findings.
+func (p *populatorWebhookPlugin) call(populator *popv1beta1.VolumePopulator, request Request) ([]Finding, error) {
+	webhook := populator.ValidationWebhook
+	url, err := webhookURL(webhook.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	client, err := p.client(populator)
+	if err != nil {
+		return nil, err
+	}
+
+	pvc := request.PVC
+	if pvc == nil {
+		pvc = &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace},
+			Spec:       *request.Spec,
+		}
+	}
+	pvcJSON, err := json.Marshal(pvc)
+	if err != nil {
+		return nil, err
+	}
+	review := &popv1beta1.DataSourceReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: popv1beta1.SchemeGroupVersion.String(),
+			Kind:       "DataSourceReview",
+		},
+		Request: &popv1beta1.DataSourceReviewRequest{
+			UID:       uuid.NewUUID(),
+			Populator: populator.Name,
+			PVC:       runtime.RawExtension{Raw: pvcJSON},
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultWebhookTimeout
+	if webhook.TimeoutSeconds != nil {
+		timeout = time.Duration(*webhook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	result := &popv1beta1.DataSourceReview{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if result.Response == nil || result.Response.UID != review.Request.UID {
+		return nil, fmt.Errorf("response does not match the request")
+	}
+
+	var findings []Finding
+	for _, warning := range result.Response.Warnings {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Reason:   ReasonPopulatorWebhookWarning,
+			Message:  warning,
+		})
+	}
+	if !result.Response.Allowed {
+		reason := result.Response.Reason
+		if reason == "" {
+			reason = ReasonPopulatorWebhookDenied
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Reason:   reason,
+			Message:  result.Response.Message,
+		})
+	}
+	return findings, nil
+}
+
+// webhookURL returns the URL of a webhook.
+func webhookURL(config popv1beta1.WebhookClientConfig) (string, error) {
+	switch {
+	case config.URL != nil && config.Service != nil:
+		return "", fmt.Errorf("only one of url and service may be set")
+	case config.URL != nil:
+		if !strings.HasPrefix(*config.URL, "https://") {
+			return "", fmt.Errorf("url must use https")
+		}
+		return *config.URL, nil
+	case config.Service != nil:
+		port := int32(443)
+		if config.Service.Port != nil {
+			port = *config.Service.Port
+		}
+		path := ""
+		if config.Service.Path != nil {
+			path = *config.Service.Path
+		}
+		return fmt.Sprintf("https://%s.%s.svc:%d%s", config.Service.Name, config.Service.Namespace, port, path), nil
+	default:
+		return "", fmt.Errorf("url or service must be set")
+	}
+}
+
+// client returns the HTTP client of the webhook of a populator. Clients are
+// reused while the CA bundle of the populator does not change, so
+// connections to the webhook are kept alive between calls. A populator
+// re-created under the same name replaces the client of the old one.
+func (p *populatorWebhookPlugin) client(populator *popv1beta1.VolumePopulator) (*http.Client, error) {
+	caBundle := populator.ValidationWebhook.ClientConfig.CABundle
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	entry, ok := p.clients[populator.Name]
+	if ok && entry.uid == populator.UID && bytes.Equal(entry.caBundle, caBundle) {
+		return entry.client, nil
+	}
+	client, err := webhookClient(caBundle)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		entry.client.CloseIdleConnections()
+	}
+	p.clients[populator.Name] = webhookClientEntry{uid: populator.UID, caBundle: caBundle, client: client}
+	return client, nil
+}
+
+// webhookClient returns an HTTP client that trusts a CA bundle, or the
+// system trust roots if it is empty.
+func webhookClient(caBundle []byte) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(caBundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("invalid caBundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
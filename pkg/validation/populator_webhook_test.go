@@ -0,0 +1,282 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+// startWebhook starts a webhook that denies PVCs named "denied", warns about
+// PVCs named "warned" and fails for PVCs named "broken".
+func startWebhook(t *testing.T, calls *int) *popv1beta1.ValidationWebhook {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		review := &popv1beta1.DataSourceReview{}
+		if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		pvc := &v1.PersistentVolumeClaim{}
+		if err := json.Unmarshal(review.Request.PVC.Raw, pvc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := &popv1beta1.DataSourceReviewResponse{UID: review.Request.UID, Allowed: true}
+		switch pvc.Name {
+		case "denied":
+			response.Allowed = false
+			response.Reason = "LicenseExpired"
+			response.Message = "The license does not cover namespace " + pvc.Namespace
+		case "warned":
+			response.Warnings = []string{"The image is large"}
+		case "broken":
+			http.Error(w, "broken", http.StatusInternalServerError)
+			return
+		}
+		review.Response = response
+		json.NewEncoder(w).Encode(review)
+	}))
+	t.Cleanup(server.Close)
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return &popv1beta1.ValidationWebhook{
+		ClientConfig: popv1beta1.WebhookClientConfig{URL: ptr.To(server.URL), CABundle: caBundle},
+	}
+}
+
+func TestPopulatorWebhookPlugin(t *testing.T) {
+	calls := 0
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.ValidationWebhook = startWebhook(t, &calls)
+	plugin, err := DefaultRegistry[PopulatorWebhookPluginName](Handle{})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	validator := New(&fakeLister{populators: []*popv1beta1.VolumePopulator{populator}}, WithPlugins(plugin))
+
+	testCases := []struct {
+		name     string
+		valid    bool
+		findings []Finding
+	}{
+		{
+			name:  "allowed",
+			valid: true,
+		},
+		{
+			name: "denied",
+			findings: []Finding{{
+				Plugin:   PopulatorWebhookPluginName,
+				Severity: SeverityError,
+				Reason:   "LicenseExpired",
+				Message:  "The license does not cover namespace default",
+			}},
+		},
+		{
+			name:  "warned",
+			valid: true,
+			findings: []Finding{{
+				Plugin:   PopulatorWebhookPluginName,
+				Severity: SeverityWarning,
+				Reason:   ReasonPopulatorWebhookWarning,
+				Message:  "The image is large",
+			}},
+		},
+		{
+			name:  "broken",
+			valid: true,
+			findings: []Finding{{
+				Plugin:   PopulatorWebhookPluginName,
+				Severity: SeverityWarning,
+				Reason:   ReasonPopulatorWebhookFailed,
+				Message:  "The validation webhook of VolumePopulator valid failed: unexpected status 500 Internal Server Error",
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pvc := &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: tc.name, UID: types.UID("uid-" + tc.name)},
+				Spec:       *makeSpec("valid.storage.k8s.io", "Valid"),
+			}
+			result, err := validator.ValidatePVC(pvc)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
+			}
+			if !reflect.DeepEqual(result.Findings, tc.findings) {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Findings, tc.findings)
+			}
+		})
+	}
+}
+
+func TestPopulatorWebhookCache(t *testing.T) {
+	calls := 0
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.ValidationWebhook = startWebhook(t, &calls)
+	now := time.Now()
+	plugin := &populatorWebhookPlugin{
+		now:     func() time.Time { return now },
+		cache:   make(map[string]webhookCacheEntry),
+		clients: make(map[string]webhookClientEntry),
+	}
+	request := func(name, resourceVersion string) Request {
+		spec := makeSpec("valid.storage.k8s.io", "Valid")
+		return Request{
+			Namespace: "default",
+			PVC: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID("uid-" + name), ResourceVersion: resourceVersion},
+				Spec:       *spec,
+			},
+			Spec:   spec,
+			Result: Result{Valid: true, Source: SourcePopulator, Populator: populator},
+		}
+	}
+
+	steps := []struct {
+		name            string
+		pvcName         string
+		resourceVersion string
+		advance         time.Duration
+		calls           int
+	}{
+		{name: "first call", pvcName: "denied", resourceVersion: "1", calls: 1},
+		{name: "cached", pvcName: "denied", resourceVersion: "1", advance: time.Minute, calls: 1},
+		{name: "other PVC", pvcName: "allowed", resourceVersion: "1", calls: 2},
+		{name: "updated PVC", pvcName: "denied", resourceVersion: "2", calls: 3},
+		{name: "expired", pvcName: "denied", resourceVersion: "1", advance: webhookCacheTTL, calls: 4},
+		{name: "failure", pvcName: "broken", resourceVersion: "1", calls: 5},
+		{name: "failures are not cached", pvcName: "broken", resourceVersion: "1", calls: 6},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if _, err := plugin.Validate(request(step.pvcName, step.resourceVersion)); err != nil {
+			t.Fatalf(`%s: expected nil error, got "%v"`, step.name, err)
+		}
+		if calls != step.calls {
+			t.Errorf(`%s: expected "%v" to equal "%v"`, step.name, calls, step.calls)
+		}
+	}
+}
+
+func TestPopulatorWebhookClient(t *testing.T) {
+	calls := 0
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.UID = "uid-valid"
+	populator.ValidationWebhook = startWebhook(t, &calls)
+	plugin := &populatorWebhookPlugin{clients: make(map[string]webhookClientEntry)}
+
+	client, err := plugin.client(populator)
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	// The client is reused while the CA bundle does not change.
+	reused, err := plugin.client(populator.DeepCopy())
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if reused != client {
+		t.Errorf("expected the client to be reused")
+	}
+	// A new CA bundle replaces the client.
+	rotated := populator.DeepCopy()
+	rotated.ValidationWebhook.ClientConfig.CABundle = append(rotated.ValidationWebhook.ClientConfig.CABundle, populator.ValidationWebhook.ClientConfig.CABundle...)
+	replaced, err := plugin.client(rotated)
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if replaced == client {
+		t.Errorf("expected the client to be replaced")
+	}
+	// A populator re-created under the same name replaces the client.
+	recreated := rotated.DeepCopy()
+	recreated.UID = "uid-recreated"
+	recreatedClient, err := plugin.client(recreated)
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if recreatedClient == replaced {
+		t.Errorf("expected the client to be replaced")
+	}
+	if len(plugin.clients) != 1 {
+		t.Errorf(`expected "%v" to equal "%v"`, len(plugin.clients), 1)
+	}
+}
+
+func TestWebhookURL(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config popv1beta1.WebhookClientConfig
+		url    string
+	}{
+		{
+			name:   "URL",
+			config: popv1beta1.WebhookClientConfig{URL: ptr.To("https://example.com/validate")},
+			url:    "https://example.com/validate",
+		},
+		{
+			name:   "Service",
+			config: popv1beta1.WebhookClientConfig{Service: &popv1beta1.ServiceReference{Namespace: "ns", Name: "populator", Path: ptr.To("/validate")}},
+			url:    "https://populator.ns.svc:443/validate",
+		},
+		{
+			name:   "Service with port",
+			config: popv1beta1.WebhookClientConfig{Service: &popv1beta1.ServiceReference{Namespace: "ns", Name: "populator", Port: ptr.To(int32(8443))}},
+			url:    "https://populator.ns.svc:8443",
+		},
+		{
+			name:   "HTTP",
+			config: popv1beta1.WebhookClientConfig{URL: ptr.To("http://example.com/validate")},
+		},
+		{
+			name: "Neither",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, err := webhookURL(tc.config)
+			if tc.url == "" {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if url != tc.url {
+				t.Errorf(`expected "%v" to equal "%v"`, url, tc.url)
+			}
+		})
+	}
+}
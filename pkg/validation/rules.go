@@ -0,0 +1,265 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// ValidationRulesPluginName is the name of the plugin that checks the
+// validationRules of VolumePopulators.
+const ValidationRulesPluginName = "ValidationRules"
+
+// Reasons of the findings of the ValidationRules plugin.
+const (
+	// ReasonValidationRuleFailed means a rule evaluated to false.
+	ReasonValidationRuleFailed = "ValidationRuleFailed"
+	// ReasonValidationRuleError means a rule could not be evaluated, for
+	// example because it uses a field the object does not have.
+	ReasonValidationRuleError = "ValidationRuleError"
+)
+
+// ruleCostLimit bounds the work a rule can do, to protect the validator from
+// expensive expressions.
+const ruleCostLimit = 1000000
+
+var (
+	ruleEnvOnce sync.Once
+	ruleEnv     *cel.Env
+	ruleEnvErr  error
+)
+
+// ruleEnvironment returns the CEL environment of validation rules.
+func ruleEnvironment() (*cel.Env, error) {
+	ruleEnvOnce.Do(func() {
+		ruleEnv, ruleEnvErr = cel.NewEnv(
+			cel.Variable("pvc", cel.DynType),
+			cel.Variable("source", cel.DynType),
+			cel.Variable("storageClass", cel.DynType),
+			ext.Strings(),
+			cel.Function("quantityValue",
+				cel.Overload("quantityValue_string", []*cel.Type{cel.StringType}, cel.IntType,
+					cel.UnaryBinding(quantityValue),
+				),
+			),
+		)
+	})
+	return ruleEnv, ruleEnvErr
+}
+
+func quantityValue(arg ref.Val) ref.Val {
+	str, ok := arg.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(arg)
+	}
+	quantity, err := resource.ParseQuantity(string(str))
+	if err != nil {
+		return types.NewErr("invalid quantity %q: %v", string(str), err)
+	}
+	return types.Int(quantity.Value())
+}
+
+// compileRule compiles a validation rule, which must return a boolean.
+func compileRule(rule string) (cel.Program, error) {
+	env, err := ruleEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(rule)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("must return a bool, not %s", ast.OutputType())
+	}
+	return env.Program(ast, cel.CostLimit(ruleCostLimit))
+}
+
+// CompileRules checks that all validation rules of a populator compile. The
+// error names the first rule that does not.
+func CompileRules(populator *popv1beta1.VolumePopulator) error {
+	for i, rule := range populator.ValidationRules {
+		if _, err := compileRule(rule.Rule); err != nil {
+			return fmt.Errorf("validationRules[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+type validationRulesPlugin struct {
+	client    kubernetes.Interface
+	dynClient dynamic.Interface
+	mapper    meta.RESTMapper
+
+	// programs caches the compiled rules of populators, by populator
+	// name.
+	lock     sync.Mutex
+	programs map[string]*populatorPrograms
+}
+
+// populatorPrograms are the compiled rules of a version of a populator.
+// errs[i] is set when the rule i does not compile.
+type populatorPrograms struct {
+	uid             apitypes.UID
+	resourceVersion string
+	programs        []cel.Program
+	errs            []error
+}
+
+func newValidationRulesPlugin(handle Handle) (Plugin, error) {
+	if handle.Client == nil || handle.DynamicClient == nil || handle.Mapper == nil {
+		return nil, fmt.Errorf("a client, a dynamic client and a REST mapper are required")
+	}
+	return &validationRulesPlugin{
+		client:    handle.Client,
+		dynClient: handle.DynamicClient,
+		mapper:    NewRefreshingRESTMapper(handle.Mapper, DefaultMapperResetInterval),
+		programs:  make(map[string]*populatorPrograms),
+	}, nil
+}
+
+func (p *validationRulesPlugin) Name() string {
+	return ValidationRulesPluginName
+}
+
+func (p *validationRulesPlugin) Validate(request Request) ([]Finding, error) {
+	populator := request.Result.Populator
+	if populator == nil || len(populator.ValidationRules) == 0 {
+		return nil, nil
+	}
+
+	vars, err := p.variables(request)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The rules would see a null source, so they are
+			// not evaluated.
+			return []Finding{kindNotServedFinding(DataSourceGroupKind(request.Spec.DataSourceRef), err)}, nil
+		}
+		return nil, err
+	}
+	compiled := p.compile(populator)
+	var findings []Finding
+	for i, rule := range populator.ValidationRules {
+		if err := compiled.errs[i]; err != nil {
+			// Reported on the VolumePopulator.
+			klog.V(4).Infof("Skipping validationRules[%d] of populator %q: %v", i, populator.Name, err)
+			continue
+		}
+		val, _, err := compiled.programs[i].Eval(vars)
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Reason:   ReasonValidationRuleError,
+				Message:  fmt.Sprintf("Rule %q of VolumePopulator %s could not be evaluated: %v", rule.Rule, populator.Name, err),
+			})
+			continue
+		}
+		if passed, ok := val.Value().(bool); !ok || !passed {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Reason:   ReasonValidationRuleFailed,
+				Message:  rule.Message,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// compile returns the compiled rules of a populator. They are compiled
+// again, replacing those of the previous version, when the populator
+// changes or is re-created.
+func (p *validationRulesPlugin) compile(populator *popv1beta1.VolumePopulator) *populatorPrograms {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	compiled, ok := p.programs[populator.Name]
+	if ok && compiled.uid == populator.UID && compiled.resourceVersion == populator.ResourceVersion && populator.ResourceVersion != "" {
+		return compiled
+	}
+	compiled = &populatorPrograms{
+		uid:             populator.UID,
+		resourceVersion: populator.ResourceVersion,
+		programs:        make([]cel.Program, len(populator.ValidationRules)),
+		errs:            make([]error, len(populator.ValidationRules)),
+	}
+	for i, rule := range populator.ValidationRules {
+		compiled.programs[i], compiled.errs[i] = compileRule(rule.Rule)
+	}
+	p.programs[populator.Name] = compiled
+	return compiled
+}
+
+// variables returns the pvc, source and storageClass variables of a request.
+func (p *validationRulesPlugin) variables(request Request) (map[string]interface{}, error) {
+	pvc := request.PVC
+	if pvc == nil {
+		pvc = &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace},
+			Spec:       *request.Spec,
+		}
+	}
+	pvcVar, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pvc)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceVar interface{}
+	source, err := getSource(p.dynClient, p.mapper, sourceNamespace(request), request.Spec.DataSourceRef)
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		sourceVar = source.Object
+	}
+
+	var storageClassVar interface{}
+	if name := request.Spec.StorageClassName; name != nil && *name != "" {
+		storageClass, err := p.client.StorageV1().StorageClasses().Get(context.TODO(), *name, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			storageClassVar, err = runtime.DefaultUnstructuredConverter.ToUnstructured(storageClass)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"pvc":          pvcVar,
+		"source":       sourceVar,
+		"storageClass": storageClassVar,
+	}, nil
+}
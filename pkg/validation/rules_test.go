@@ -0,0 +1,228 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestCompileRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules []string
+		valid bool
+	}{
+		{
+			name:  "Valid",
+			rules: []string{"pvc.spec.volumeMode == 'Block'", "source == null || source.spec.format == 'raw'"},
+			valid: true,
+		},
+		{
+			name:  "Syntax error",
+			rules: []string{"pvc.spec.volumeMode =="},
+		},
+		{
+			name:  "Unknown variable",
+			rules: []string{"volume.spec.size > 0"},
+		},
+		{
+			name:  "Not a bool",
+			rules: []string{"quantityValue('1Gi')"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+			for _, rule := range tc.rules {
+				populator.ValidationRules = append(populator.ValidationRules, popv1beta1.ValidationRule{Rule: rule})
+			}
+			err := CompileRules(populator)
+			if (err == nil) != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v": %v`, err == nil, tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestValidationRulesPlugin(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "valid.storage.k8s.io", Version: "v1", Resource: "valids"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvr.GroupVersion()})
+	mapper.Add(gvr.GroupVersion().WithKind("Valid"), meta.RESTScopeNamespace)
+	source := &unstructured.Unstructured{}
+	source.SetAPIVersion("valid.storage.k8s.io/v1")
+	source.SetKind("Valid")
+	source.SetNamespace("default")
+	source.SetName("source")
+	unstructured.SetNestedField(source.Object, "2Gi", "spec", "virtualSize")
+	unstructured.SetNestedField(source.Object, "raw", "spec", "format")
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "ValidList"}, source)
+	client := fake.NewSimpleClientset(&storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "fast"},
+		Provisioner: "csi.example.com",
+	})
+
+	plugin, err := DefaultRegistry[ValidationRulesPluginName](Handle{Client: client, DynamicClient: dynClient, Mapper: mapper})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.ValidationRules = []popv1beta1.ValidationRule{
+		{Rule: "source == null || quantityValue(pvc.spec.resources.requests.storage) >= quantityValue(source.spec.virtualSize)", Message: "The PVC must be at least as large as the image"},
+		{Rule: "!has(pvc.spec.volumeMode) || pvc.spec.volumeMode != 'Block' || source.spec.format == 'raw'", Message: "Block volumes need a raw image"},
+		{Rule: "storageClass == null || storageClass.provisioner == 'csi.example.com'", Message: "Unsupported provisioner"},
+		{Rule: "pvc.spec.volumeMode ==", Message: "Does not compile"},
+	}
+	validator := New(&fakeLister{populators: []*popv1beta1.VolumePopulator{populator}}, WithPlugins(plugin))
+
+	testCases := []struct {
+		name         string
+		size         string
+		storageClass string
+		namespace    string
+		valid        bool
+		findings     []string
+	}{
+		{
+			name:         "Large enough",
+			size:         "2Gi",
+			storageClass: "fast",
+			namespace:    "default",
+			valid:        true,
+		},
+		{
+			name:      "Too small",
+			size:      "1Gi",
+			namespace: "default",
+			findings:  []string{"The PVC must be at least as large as the image"},
+		},
+		{
+			name:         "Missing storage class",
+			size:         "1Gi",
+			storageClass: "slow",
+			namespace:    "other",
+			valid:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := makeSpec("valid.storage.k8s.io", "Valid")
+			spec.Resources.Requests = v1.ResourceList{v1.ResourceStorage: resource.MustParse(tc.size)}
+			if tc.storageClass != "" {
+				spec.StorageClassName = ptr.To(tc.storageClass)
+			}
+			result, err := validator.ValidateSpec(tc.namespace, spec)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
+			}
+			var findings []string
+			for _, finding := range result.Findings {
+				findings = append(findings, finding.Message)
+			}
+			if !reflect.DeepEqual(findings, tc.findings) {
+				t.Errorf(`expected "%v" to equal "%v"`, findings, tc.findings)
+			}
+		})
+	}
+
+	// Rules are not evaluated with a null source when the kind of the
+	// source is not served.
+	unknown := makePopulator("unknown", "unknown.storage.k8s.io", "Unknown")
+	unknown.ValidationRules = populator.ValidationRules[:1]
+	findings, err := plugin.Validate(Request{
+		Namespace: "default",
+		Spec:      makeSpec("unknown.storage.k8s.io", "Unknown"),
+		Result:    Result{Valid: true, Source: SourcePopulator, Populator: unknown},
+	})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if len(findings) != 1 || findings[0].Reason != ReasonDataSourceKindNotServed || findings[0].Severity != SeverityWarning {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
+
+func TestValidationRulesEvalError(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "valid.storage.k8s.io", Version: "v1", Resource: "valids"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvr.GroupVersion()})
+	mapper.Add(gvr.GroupVersion().WithKind("Valid"), meta.RESTScopeNamespace)
+	dynClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "ValidList"})
+	plugin, err := DefaultRegistry[ValidationRulesPluginName](Handle{Client: fake.NewSimpleClientset(), DynamicClient: dynClient, Mapper: mapper})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.ValidationRules = []popv1beta1.ValidationRule{{Rule: "source.spec.format == 'raw'", Message: "raw only"}}
+
+	findings, err := plugin.Validate(Request{
+		Namespace: "default",
+		Spec:      makeSpec("valid.storage.k8s.io", "Valid"),
+		Result:    Result{Valid: true, Source: SourcePopulator, Populator: populator},
+	})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if len(findings) != 1 || findings[0].Reason != ReasonValidationRuleError || findings[0].Severity != SeverityWarning {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
+
+func TestValidationRulesProgramCache(t *testing.T) {
+	plugin := &validationRulesPlugin{programs: make(map[string]*populatorPrograms)}
+	populator := makePopulator("valid", "valid.storage.k8s.io", "Valid")
+	populator.UID = "uid-valid"
+	populator.ResourceVersion = "1"
+	populator.ValidationRules = []popv1beta1.ValidationRule{{Rule: "source == null", Message: "no source"}}
+
+	compiled := plugin.compile(populator)
+	if cached := plugin.compile(populator.DeepCopy()); cached != compiled {
+		t.Errorf("expected the rules to be reused")
+	}
+
+	// A new version of the populator replaces its rules.
+	updated := populator.DeepCopy()
+	updated.ResourceVersion = "2"
+	updated.ValidationRules = append(updated.ValidationRules, popv1beta1.ValidationRule{Rule: "pvc.spec.volumeMode ==", Message: "Does not compile"})
+	recompiled := plugin.compile(updated)
+	if recompiled == compiled {
+		t.Errorf("expected the rules to be compiled again")
+	}
+	if len(recompiled.programs) != 2 || recompiled.errs[0] != nil || recompiled.errs[1] == nil {
+		t.Errorf("unexpected programs %v, errors %v", recompiled.programs, recompiled.errs)
+	}
+	if len(plugin.programs) != 1 {
+		t.Errorf(`expected "%v" to equal "%v"`, len(plugin.programs), 1)
+	}
+}
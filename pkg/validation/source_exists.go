@@ -0,0 +1,113 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// SourceExistsPluginName is the name of the plugin that warns about data
+// sources that do not exist.
+const SourceExistsPluginName = "SourceExists"
+
+type sourceExistsPlugin struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+func newSourceExistsPlugin(handle Handle) (Plugin, error) {
+	if handle.DynamicClient == nil || handle.Mapper == nil {
+		return nil, fmt.Errorf("a dynamic client and a REST mapper are required")
+	}
+	return &sourceExistsPlugin{
+		client: handle.DynamicClient,
+		mapper: NewRefreshingRESTMapper(handle.Mapper, DefaultMapperResetInterval),
+	}, nil
+}
+
+func (p *sourceExistsPlugin) Name() string {
+	return SourceExistsPluginName
+}
+
+func (p *sourceExistsPlugin) Validate(request Request) ([]Finding, error) {
+	dataSourceRef := request.Spec.DataSourceRef
+	gk := DataSourceGroupKind(dataSourceRef)
+	namespace := sourceNamespace(request)
+	source, err := getSource(p.client, p.mapper, namespace, dataSourceRef)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return []Finding{kindNotServedFinding(gk, err)}, nil
+		}
+		return nil, err
+	}
+	if source != nil {
+		return nil, nil
+	}
+	return []Finding{{
+		Severity: SeverityWarning,
+		Reason:   "DataSourceNotFound",
+		Message:  fmt.Sprintf("The datasource %s %s/%s of this PVC does not exist", gk.String(), namespace, dataSourceRef.Name),
+	}}, nil
+}
+
+// kindNotServedFinding reports a data source whose kind is not served,
+// after the mapper was reset to look for it.
+func kindNotServedFinding(gk metav1.GroupKind, err error) Finding {
+	return Finding{
+		Severity: SeverityWarning,
+		Reason:   ReasonDataSourceKindNotServed,
+		Message:  fmt.Sprintf("The datasource kind %s of this PVC is not served: %v", gk.String(), err),
+	}
+}
+
+// sourceNamespace returns the namespace of the data source of a request.
+func sourceNamespace(request Request) string {
+	if request.Spec.DataSourceRef.Namespace != nil && *request.Spec.DataSourceRef.Namespace != "" {
+		return *request.Spec.DataSourceRef.Namespace
+	}
+	return request.Namespace
+}
+
+// getSource returns a data source object, or nil if it does not exist.
+func getSource(client dynamic.Interface, mapper meta.RESTMapper, namespace string, dataSourceRef *v1.TypedObjectReference) (*unstructured.Unstructured, error) {
+	gk := DataSourceGroupKind(dataSourceRef)
+	mapping, err := mapper.RESTMapping(schema.GroupKind{Group: gk.Group, Kind: gk.Kind})
+	if err != nil {
+		return nil, err
+	}
+	var resource dynamic.ResourceInterface = client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		resource = client.Resource(mapping.Resource).Namespace(namespace)
+	}
+	source, err := resource.Get(context.TODO(), dataSourceRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return source, nil
+}
@@ -0,0 +1,417 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation checks whether the data source of a PVC is one that
+// will be populated, either because it is a kind handled by Kubernetes
+// itself or because a VolumePopulator registers it.
+//
+// Apart from DefaultRegistry, the package has no global state and it does
+// not start informers, so it can be used by admission webhooks, populators
+// and binaries that run several controllers. Registrations are read through
+// a PopulatorLister, for example one backed by a dynamic informer:
+//
+//	lister := validation.NewDynamicPopulatorLister(
+//		dynamiclister.New(informer.GetIndexer(), resource))
+//	validator := validation.New(lister)
+//	result, err := validator.ValidatePVC(pvc)
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamiclister"
+	"k8s.io/klog/v2"
+)
+
+// Reasons of invalid results.
+const (
+	// ReasonUnrecognizedDataSourceKind means no VolumePopulator registers
+	// the kind of the data source.
+	ReasonUnrecognizedDataSourceKind = "UnrecognizedDataSourceKind"
+	// ReasonDataSourceKindNotServed means a VolumePopulator registers
+	// the kind of the data source, but the API server does not serve it.
+	ReasonDataSourceKindNotServed = "DataSourceKindNotServed"
+	// ReasonPopulatorSuspended means the VolumePopulator that registers
+	// the kind of the data source is suspended.
+	ReasonPopulatorSuspended = "PopulatorSuspended"
+)
+
+// SourceType says how a valid data source is populated.
+type SourceType string
+
+const (
+	// SourceNone is a PVC without a data source.
+	SourceNone SourceType = ""
+	// SourcePersistentVolumeClaim is a clone of another PVC.
+	SourcePersistentVolumeClaim SourceType = "PersistentVolumeClaim"
+	// SourceVolumeSnapshot is a restore of a VolumeSnapshot.
+	SourceVolumeSnapshot SourceType = "VolumeSnapshot"
+	// SourcePopulator is a kind registered by a VolumePopulator.
+	SourcePopulator SourceType = "VolumePopulator"
+	// SourceNamespacedPopulator is a kind registered by a
+	// NamespacedVolumePopulator in the namespace of the PVC.
+	SourceNamespacedPopulator SourceType = "NamespacedVolumePopulator"
+)
+
+var (
+	pvcGK            = metav1.GroupKind{Group: v1.GroupName, Kind: "PersistentVolumeClaim"}
+	volumeSnapshotGK = metav1.GroupKind{Group: volumesnapshotv1.GroupName, Kind: "VolumeSnapshot"}
+)
+
+// Result is the outcome of validating a data source.
+type Result struct {
+	// Valid is true if the data source will be populated.
+	Valid bool
+	// Source says how a valid data source is populated.
+	Source SourceType
+	// Populator is the registration that matched a valid data source of
+	// type SourcePopulator, or the one that matched but could not be used
+	// for invalid results.
+	Populator *popv1beta1.VolumePopulator
+	// NamespacedPopulator is the registration that matched a valid data
+	// source of type SourceNamespacedPopulator.
+	NamespacedPopulator *popv1beta1.NamespacedVolumePopulator
+	// Reason is a CamelCase code for invalid results.
+	Reason string
+	// Message explains invalid results.
+	Message string
+	// Findings are reported by plugins, in the order they ran. An Error
+	// finding makes the result invalid, with its reason and message.
+	Findings []Finding
+}
+
+// Validator validates the data sources of PVCs.
+type Validator interface {
+	// ValidatePVC validates the dataSourceRef of a PVC.
+	ValidatePVC(pvc *v1.PersistentVolumeClaim) (Result, error)
+	// ValidateSpec validates the dataSourceRef of a PVC spec, for example
+	// of a template, that will be created in the given namespace.
+	ValidateSpec(namespace string, spec *v1.PersistentVolumeClaimSpec) (Result, error)
+	// ValidateGroupKind validates a data source kind. Plugins are not run,
+	// as they need the full data source, and NamespacedVolumePopulators are
+	// not considered, as they need the namespace of the PVC.
+	ValidateGroupKind(gk metav1.GroupKind) (Result, error)
+}
+
+// PopulatorLister lists VolumePopulator registrations.
+type PopulatorLister interface {
+	// List returns all VolumePopulators, sorted by name.
+	List() ([]*popv1beta1.VolumePopulator, error)
+}
+
+// NamespacedPopulatorLister lists NamespacedVolumePopulator registrations.
+type NamespacedPopulatorLister interface {
+	// List returns the NamespacedVolumePopulators of a namespace, sorted
+	// by name.
+	List(namespace string) ([]*popv1beta1.NamespacedVolumePopulator, error)
+}
+
+// SourceKindChecker checks that the API server serves the source kind of a
+// populator. It returns the reason and a message explaining why if not.
+type SourceKindChecker interface {
+	SourceKindServed(populator *popv1beta1.VolumePopulator) (served bool, reason, message string, err error)
+}
+
+// Option configures optional checks of a Validator.
+type Option func(v *validator)
+
+// WithSourceKindChecker makes the validator reject data sources whose kind
+// is registered, but not served.
+func WithSourceKindChecker(checker SourceKindChecker) Option {
+	return func(v *validator) {
+		v.sourceKinds = checker
+	}
+}
+
+// WithNamespacedPopulators makes the validator accept data sources whose
+// kind is registered by a NamespacedVolumePopulator in the namespace of the
+// PVC, if no VolumePopulator registers it.
+func WithNamespacedPopulators(lister NamespacedPopulatorLister) Option {
+	return func(v *validator) {
+		v.namespacedPopulators = lister
+	}
+}
+
+type validator struct {
+	populators           PopulatorLister
+	namespacedPopulators NamespacedPopulatorLister
+	sourceKinds          SourceKindChecker
+	plugins              []Plugin
+}
+
+// New returns a Validator that reads registrations from populators.
+func New(populators PopulatorLister, opts ...Option) Validator {
+	v := &validator{populators: populators}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+func (v *validator) ValidatePVC(pvc *v1.PersistentVolumeClaim) (Result, error) {
+	return v.validate(pvc.Namespace, &pvc.Spec, pvc)
+}
+
+func (v *validator) ValidateSpec(namespace string, spec *v1.PersistentVolumeClaimSpec) (Result, error) {
+	return v.validate(namespace, spec, nil)
+}
+
+func (v *validator) validate(namespace string, spec *v1.PersistentVolumeClaimSpec, pvc *v1.PersistentVolumeClaim) (Result, error) {
+	if spec.DataSourceRef == nil {
+		return Result{Valid: true, Source: SourceNone}, nil
+	}
+	result, err := v.validateGroupKind(namespace, DataSourceGroupKind(spec.DataSourceRef))
+	if err != nil || !result.Valid || len(v.plugins) == 0 {
+		return result, err
+	}
+	return v.runPlugins(Request{Namespace: namespace, PVC: pvc, Spec: spec, Result: result})
+}
+
+func (v *validator) ValidateGroupKind(gk metav1.GroupKind) (Result, error) {
+	return v.validateGroupKind("", gk)
+}
+
+// validateGroupKind validates a data source kind for a PVC in the given
+// namespace. Registrations are matched in this order: VolumePopulators of
+// the exact kind, wildcard VolumePopulators of its group, and
+// NamespacedVolumePopulators of the namespace. The latter are only
+// considered if the namespace is not empty.
+func (v *validator) validateGroupKind(namespace string, gk metav1.GroupKind) (Result, error) {
+	// Cloning PVCs and Volume Snapshots are special cases, allowed by the
+	// core, so don't reject these.
+	switch gk {
+	case pvcGK:
+		klog.V(4).Infof("Allowing PVC as a special case")
+		return Result{Valid: true, Source: SourcePersistentVolumeClaim}, nil
+	case volumeSnapshotGK:
+		klog.V(4).Infof("Allowing VolumeSnapshot as a special case")
+		return Result{Valid: true, Source: SourceVolumeSnapshot}, nil
+	}
+	populators, err := v.populators.List()
+	if err != nil {
+		klog.Errorf("Failed to list populators: %v", err)
+		return Result{}, err
+	}
+	matched := MatchPopulators(populators, gk)
+	if len(matched) == 0 && v.namespacedPopulators != nil && namespace != "" {
+		namespaced, err := v.namespacedPopulators.List(namespace)
+		if err != nil {
+			klog.Errorf("Failed to list populators of namespace %s: %v", namespace, err)
+			return Result{}, err
+		}
+		for _, populator := range namespaced {
+			if populator.SourceKind == gk {
+				klog.V(4).Infof("Allowing %q due to %q populator in namespace %s", gk.String(), populator.Name, namespace)
+				return Result{Valid: true, Source: SourceNamespacedPopulator, NamespacedPopulator: populator}, nil
+			}
+		}
+	}
+	if len(matched) == 0 {
+		klog.Warningf("No populator matches %s", gk.String())
+		return Result{
+			Reason:  ReasonUnrecognizedDataSourceKind,
+			Message: "The datasource for this PVC does not match any registered VolumePopulator",
+		}, nil
+	}
+	if len(matched) > 1 {
+		klog.Warningf("%d populators register %s, using %s", len(matched), gk.String(), DescribePopulator(matched[0]))
+	}
+	populator := matched[0]
+
+	// A suspended registration still shadows other registrations of the
+	// kind, so that suspending it makes the kind unavailable.
+	if populator.Suspended {
+		reason := populator.SuspendReason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		klog.Warningf("%s matches %s, but is suspended: %s", DescribePopulator(populator), gk.String(), reason)
+		return Result{
+			Populator: populator,
+			Reason:    ReasonPopulatorSuspended,
+			Message:   fmt.Sprintf("The datasource kind of this PVC is registered by %s, which is suspended: %s", DescribePopulator(populator), reason),
+		}, nil
+	}
+
+	if v.sourceKinds != nil {
+		// A wildcard registration is checked for the kind of the PVC.
+		checked := populator
+		if IsWildcard(populator) {
+			checked = populator.DeepCopy()
+			checked.SourceKind = gk
+		}
+		served, _, message, err := v.sourceKinds.SourceKindServed(checked)
+		if err != nil {
+			klog.Errorf("Failed to check if %s is served: %v", gk.String(), err)
+			return Result{}, err
+		}
+		if !served {
+			klog.Warningf("%s matches %s, but: %s", DescribePopulator(populator), gk.String(), message)
+			return Result{
+				Populator: populator,
+				Reason:    ReasonDataSourceKindNotServed,
+				Message:   fmt.Sprintf("The datasource kind of this PVC is registered by %s, but is not served: %s", DescribePopulator(populator), message),
+			}, nil
+		}
+	}
+
+	klog.V(4).Infof("Allowing %q due to %s", gk.String(), DescribePopulator(populator))
+	return Result{Valid: true, Source: SourcePopulator, Populator: populator}, nil
+}
+
+// MatchPopulators returns the VolumePopulators that register a data source
+// kind. Registrations of the exact kind take precedence over wildcard
+// registrations of its group: wildcards are only returned when no populator
+// registers the exact kind. The order of populators is kept.
+func MatchPopulators(populators []*popv1beta1.VolumePopulator, gk metav1.GroupKind) []*popv1beta1.VolumePopulator {
+	var exact, wildcards []*popv1beta1.VolumePopulator
+	for _, populator := range populators {
+		switch {
+		case populator.SourceKind == gk:
+			exact = append(exact, populator)
+		case Matches(populator, gk):
+			wildcards = append(wildcards, populator)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return wildcards
+}
+
+// Matches returns whether a VolumePopulator registers a data source kind,
+// either exactly or with a wildcard for the group of the kind that does not
+// exclude it. It does not consider the precedence of exact registrations,
+// see MatchPopulators.
+func Matches(populator *popv1beta1.VolumePopulator, gk metav1.GroupKind) bool {
+	if populator.SourceKind == gk {
+		return true
+	}
+	if !IsWildcard(populator) || populator.SourceKind.Group != gk.Group {
+		return false
+	}
+	for _, excluded := range populator.ExcludedKinds {
+		if excluded == gk.Kind {
+			return false
+		}
+	}
+	return true
+}
+
+// IsWildcard returns whether a VolumePopulator registers all kinds of a
+// group.
+func IsWildcard(populator *popv1beta1.VolumePopulator) bool {
+	return populator.SourceKind.Kind == popv1beta1.WildcardKind
+}
+
+// DescribePopulator names a VolumePopulator in messages, saying whether it
+// is a wildcard registration.
+func DescribePopulator(populator *popv1beta1.VolumePopulator) string {
+	if IsWildcard(populator) {
+		return fmt.Sprintf("VolumePopulator %s (wildcard for group %s)", populator.Name, populator.SourceKind.Group)
+	}
+	return fmt.Sprintf("VolumePopulator %s", populator.Name)
+}
+
+// DataSourceGroupKind returns the GroupKind of a dataSourceRef.
+func DataSourceGroupKind(dataSourceRef *v1.TypedObjectReference) metav1.GroupKind {
+	apiGroup := ""
+	if dataSourceRef.APIGroup != nil {
+		apiGroup = *dataSourceRef.APIGroup
+	}
+	return metav1.GroupKind{
+		Group: apiGroup,
+		Kind:  dataSourceRef.Kind,
+	}
+}
+
+type dynamicPopulatorLister struct {
+	lister dynamiclister.Lister
+}
+
+// NewDynamicPopulatorLister returns a PopulatorLister that reads
+// VolumePopulators from a dynamic lister.
+func NewDynamicPopulatorLister(lister dynamiclister.Lister) PopulatorLister {
+	return &dynamicPopulatorLister{lister: lister}
+}
+
+func (l *dynamicPopulatorLister) List() ([]*popv1beta1.VolumePopulator, error) {
+	unstPopulators, err := l.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	populators := make([]*popv1beta1.VolumePopulator, 0, len(unstPopulators))
+	for _, unstPopulator := range unstPopulators {
+		populator, err := ConvertPopulator(unstPopulator)
+		if err != nil {
+			return nil, err
+		}
+		populators = append(populators, populator)
+	}
+	sort.Slice(populators, func(i, j int) bool {
+		return populators[i].Name < populators[j].Name
+	})
+	return populators, nil
+}
+
+// ConvertPopulator converts a VolumePopulator read through the dynamic
+// client.
+func ConvertPopulator(unstPopulator *unstructured.Unstructured) (*popv1beta1.VolumePopulator, error) {
+	var populator popv1beta1.VolumePopulator
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstPopulator.UnstructuredContent(), &populator)
+	if err != nil {
+		return nil, err
+	}
+	return &populator, nil
+}
+
+type dynamicNamespacedPopulatorLister struct {
+	lister dynamiclister.Lister
+}
+
+// NewDynamicNamespacedPopulatorLister returns a NamespacedPopulatorLister
+// that reads NamespacedVolumePopulators from a dynamic lister.
+func NewDynamicNamespacedPopulatorLister(lister dynamiclister.Lister) NamespacedPopulatorLister {
+	return &dynamicNamespacedPopulatorLister{lister: lister}
+}
+
+func (l *dynamicNamespacedPopulatorLister) List(namespace string) ([]*popv1beta1.NamespacedVolumePopulator, error) {
+	unstPopulators, err := l.lister.Namespace(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	populators := make([]*popv1beta1.NamespacedVolumePopulator, 0, len(unstPopulators))
+	for _, unstPopulator := range unstPopulators {
+		var populator popv1beta1.NamespacedVolumePopulator
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstPopulator.UnstructuredContent(), &populator)
+		if err != nil {
+			return nil, err
+		}
+		populators = append(populators, &populator)
+	}
+	sort.Slice(populators, func(i, j int) bool {
+		return populators[i].Name < populators[j].Name
+	})
+	return populators, nil
+}
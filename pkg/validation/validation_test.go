@@ -0,0 +1,343 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+type fakeLister struct {
+	populators []*popv1beta1.VolumePopulator
+	err        error
+}
+
+func (l *fakeLister) List() ([]*popv1beta1.VolumePopulator, error) {
+	return l.populators, l.err
+}
+
+type fakeNamespacedLister struct {
+	populators []*popv1beta1.NamespacedVolumePopulator
+}
+
+func (l *fakeNamespacedLister) List(namespace string) ([]*popv1beta1.NamespacedVolumePopulator, error) {
+	var populators []*popv1beta1.NamespacedVolumePopulator
+	for _, populator := range l.populators {
+		if populator.Namespace == namespace {
+			populators = append(populators, populator)
+		}
+	}
+	return populators, nil
+}
+
+type fakeChecker struct {
+	served bool
+}
+
+func (c *fakeChecker) SourceKindServed(*popv1beta1.VolumePopulator) (bool, string, string, error) {
+	return c.served, "KindNotServed", "not installed", nil
+}
+
+type recordingChecker struct {
+	served  bool
+	checked metav1.GroupKind
+}
+
+func (c *recordingChecker) SourceKindServed(populator *popv1beta1.VolumePopulator) (bool, string, string, error) {
+	c.checked = populator.SourceKind
+	return c.served, "KindNotServed", "not installed", nil
+}
+
+func makePopulator(name, group, kind string) *popv1beta1.VolumePopulator {
+	return &popv1beta1.VolumePopulator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		SourceKind: metav1.GroupKind{Group: group, Kind: kind},
+	}
+}
+
+func makeSpec(group, kind string) *v1.PersistentVolumeClaimSpec {
+	return &v1.PersistentVolumeClaimSpec{
+		DataSourceRef: &v1.TypedObjectReference{APIGroup: ptr.To(group), Kind: kind, Name: "source"},
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	lister := &fakeLister{populators: []*popv1beta1.VolumePopulator{makePopulator("valid", "valid.storage.k8s.io", "Valid")}}
+
+	testCases := []struct {
+		name      string
+		spec      *v1.PersistentVolumeClaimSpec
+		served    *bool
+		valid     bool
+		source    SourceType
+		reason    string
+		populator string
+	}{
+		{
+			name:  "No data source",
+			spec:  &v1.PersistentVolumeClaimSpec{},
+			valid: true,
+		},
+		{
+			name:   "PVC",
+			spec:   makeSpec("", "PersistentVolumeClaim"),
+			valid:  true,
+			source: SourcePersistentVolumeClaim,
+		},
+		{
+			name:   "VolumeSnapshot",
+			spec:   makeSpec("snapshot.storage.k8s.io", "VolumeSnapshot"),
+			valid:  true,
+			source: SourceVolumeSnapshot,
+		},
+		{
+			name:      "Registered kind",
+			spec:      makeSpec("valid.storage.k8s.io", "Valid"),
+			valid:     true,
+			source:    SourcePopulator,
+			populator: "valid",
+		},
+		{
+			name:   "Unregistered kind",
+			spec:   makeSpec("invalid.storage.k8s.io", "Invalid"),
+			reason: ReasonUnrecognizedDataSourceKind,
+		},
+		{
+			name:      "Registered kind that is not served",
+			spec:      makeSpec("valid.storage.k8s.io", "Valid"),
+			served:    ptr.To(false),
+			reason:    ReasonDataSourceKindNotServed,
+			populator: "valid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []Option
+			if tc.served != nil {
+				opts = append(opts, WithSourceKindChecker(&fakeChecker{served: *tc.served}))
+			}
+			result, err := New(lister, opts...).ValidateSpec("default", tc.spec)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
+			}
+			if result.Source != tc.source {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Source, tc.source)
+			}
+			if result.Reason != tc.reason {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Reason, tc.reason)
+			}
+			populator := ""
+			if result.Populator != nil {
+				populator = result.Populator.Name
+			}
+			if populator != tc.populator {
+				t.Errorf(`expected "%v" to equal "%v"`, populator, tc.populator)
+			}
+		})
+	}
+}
+
+func TestValidateNamespacedPopulators(t *testing.T) {
+	lister := &fakeLister{populators: []*popv1beta1.VolumePopulator{makePopulator("valid", "valid.storage.k8s.io", "Valid")}}
+	namespaced := &fakeNamespacedLister{populators: []*popv1beta1.NamespacedVolumePopulator{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "tenant"},
+			SourceKind: metav1.GroupKind{Group: "tenant.example.com", Kind: "Image"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "shadowed"},
+			SourceKind: metav1.GroupKind{Group: "valid.storage.k8s.io", Kind: "Valid"},
+		},
+	}}
+	validator := New(lister, WithNamespacedPopulators(namespaced))
+
+	testCases := []struct {
+		name      string
+		namespace string
+		spec      *v1.PersistentVolumeClaimSpec
+		valid     bool
+		source    SourceType
+		populator string
+	}{
+		{
+			name:      "Kind registered in the namespace",
+			namespace: "tenant",
+			spec:      makeSpec("tenant.example.com", "Image"),
+			valid:     true,
+			source:    SourceNamespacedPopulator,
+			populator: "tenant",
+		},
+		{
+			name:      "Kind registered in another namespace",
+			namespace: "default",
+			spec:      makeSpec("tenant.example.com", "Image"),
+		},
+		{
+			name:      "Cluster registration takes precedence",
+			namespace: "tenant",
+			spec:      makeSpec("valid.storage.k8s.io", "Valid"),
+			valid:     true,
+			source:    SourcePopulator,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := validator.ValidateSpec(tc.namespace, tc.spec)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
+			}
+			if result.Source != tc.source {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Source, tc.source)
+			}
+			populator := ""
+			if result.NamespacedPopulator != nil {
+				populator = result.NamespacedPopulator.Name
+			}
+			if populator != tc.populator {
+				t.Errorf(`expected "%v" to equal "%v"`, populator, tc.populator)
+			}
+		})
+	}
+
+	// Without a namespace, only VolumePopulators are considered.
+	result, err := validator.ValidateGroupKind(metav1.GroupKind{Group: "tenant.example.com", Kind: "Image"})
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if result.Valid {
+		t.Errorf("expected an invalid result")
+	}
+}
+
+func TestValidateWildcardPopulators(t *testing.T) {
+	wildcard := makePopulator("vendor", "vendor.example.com", popv1beta1.WildcardKind)
+	wildcard.ExcludedKinds = []string{"Internal"}
+	lister := &fakeLister{populators: []*popv1beta1.VolumePopulator{
+		makePopulator("exact", "vendor.example.com", "Backup"),
+		wildcard,
+	}}
+	checker := &recordingChecker{served: true}
+	validator := New(lister, WithSourceKindChecker(checker))
+
+	testCases := []struct {
+		name      string
+		spec      *v1.PersistentVolumeClaimSpec
+		valid     bool
+		populator string
+	}{
+		{
+			name:      "Exact registration takes precedence",
+			spec:      makeSpec("vendor.example.com", "Backup"),
+			valid:     true,
+			populator: "exact",
+		},
+		{
+			name:      "Kind of the group",
+			spec:      makeSpec("vendor.example.com", "Image"),
+			valid:     true,
+			populator: "vendor",
+		},
+		{
+			name: "Excluded kind",
+			spec: makeSpec("vendor.example.com", "Internal"),
+		},
+		{
+			name: "Kind of another group",
+			spec: makeSpec("other.example.com", "Image"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker.checked = metav1.GroupKind{}
+			result, err := validator.ValidateSpec("default", tc.spec)
+			if err != nil {
+				t.Fatalf(`expected nil error, got "%v"`, err)
+			}
+			if result.Valid != tc.valid {
+				t.Errorf(`expected "%v" to equal "%v"`, result.Valid, tc.valid)
+			}
+			populator := ""
+			if result.Populator != nil {
+				populator = result.Populator.Name
+			}
+			if populator != tc.populator {
+				t.Errorf(`expected "%v" to equal "%v"`, populator, tc.populator)
+			}
+			// Wildcards are checked for the kind of the PVC.
+			if tc.valid && checker.checked != DataSourceGroupKind(tc.spec.DataSourceRef) {
+				t.Errorf(`expected "%v" to equal "%v"`, checker.checked, DataSourceGroupKind(tc.spec.DataSourceRef))
+			}
+		})
+	}
+
+	checker.served = false
+	result, err := validator.ValidateSpec("default", makeSpec("vendor.example.com", "Image"))
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	expected := "The datasource kind of this PVC is registered by VolumePopulator vendor (wildcard for group vendor.example.com), but is not served: not installed"
+	if result.Message != expected {
+		t.Errorf(`expected "%v" to equal "%v"`, result.Message, expected)
+	}
+}
+
+func TestValidateSuspendedPopulator(t *testing.T) {
+	suspended := makePopulator("exact", "vendor.example.com", "Backup")
+	suspended.Suspended = true
+	suspended.SuspendReason = "INC-42"
+	lister := &fakeLister{populators: []*popv1beta1.VolumePopulator{
+		suspended,
+		makePopulator("vendor", "vendor.example.com", popv1beta1.WildcardKind),
+	}}
+
+	// The wildcard does not take over the suspended kind.
+	result, err := New(lister).ValidateSpec("default", makeSpec("vendor.example.com", "Backup"))
+	if err != nil {
+		t.Fatalf(`expected nil error, got "%v"`, err)
+	}
+	if result.Valid {
+		t.Errorf("expected an invalid result")
+	}
+	if result.Reason != ReasonPopulatorSuspended {
+		t.Errorf(`expected "%v" to equal "%v"`, result.Reason, ReasonPopulatorSuspended)
+	}
+	expected := "The datasource kind of this PVC is registered by VolumePopulator exact, which is suspended: INC-42"
+	if result.Message != expected {
+		t.Errorf(`expected "%v" to equal "%v"`, result.Message, expected)
+	}
+}
+
+func TestListError(t *testing.T) {
+	validator := New(&fakeLister{err: errors.New("failed")})
+	if _, err := validator.ValidatePVC(&v1.PersistentVolumeClaim{Spec: *makeSpec("valid.storage.k8s.io", "Valid")}); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// PopulatorValidator validates changes to VolumePopulators.
+type PopulatorValidator interface {
+	// ValidatePopulatorChange returns the problems that reject a change to
+	// a VolumePopulator, and warnings about it. oldPopulator is nil on
+	// creation and newPopulator is nil on deletion.
+	ValidatePopulatorChange(oldPopulator, newPopulator *popv1beta1.VolumePopulator) (problems []string, warnings []string, err error)
+}
+
+// AdmitPopulators returns an admission handler for VolumePopulators. It
+// rejects creations, updates and deletions with problems, for example a
+// source kind that is already registered, and returns warnings otherwise.
+// NamespacedVolumePopulators are allowed as they are: they only carry a
+// source kind, so several of them registering the same kind in a namespace
+// have the same effect as one.
+func AdmitPopulators(validator PopulatorValidator) AdmitFunc {
+	return func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		if request.Kind.Group != popv1beta1.GroupName || request.Kind.Kind != "VolumePopulator" {
+			return allowed()
+		}
+
+		var oldPopulator, newPopulator *popv1beta1.VolumePopulator
+		var err error
+		switch request.Operation {
+		case admissionv1.Create:
+			newPopulator, err = decodePopulator(request.Object)
+		case admissionv1.Update:
+			if oldPopulator, err = decodePopulator(request.OldObject); err == nil {
+				newPopulator, err = decodePopulator(request.Object)
+			}
+		case admissionv1.Delete:
+			oldPopulator, err = decodePopulator(request.OldObject)
+		default:
+			return allowed()
+		}
+		if err != nil {
+			return denied(metav1.StatusReasonBadRequest, "failed to decode VolumePopulator: %v", err)
+		}
+
+		problems, warnings, err := validator.ValidatePopulatorChange(oldPopulator, newPopulator)
+		if err != nil {
+			// The CRD schema still validates the object, do not
+			// block registrations while the caches are unavailable.
+			klog.Errorf("Failed to validate VolumePopulator %s: %v", request.Name, err)
+			return allowed()
+		}
+		if len(problems) > 0 {
+			response := denied(metav1.StatusReasonForbidden, "%s", strings.Join(problems, "; "))
+			response.Warnings = warnings
+			return response
+		}
+		return allowed(warnings...)
+	}
+}
+
+func decodePopulator(raw runtime.RawExtension) (*popv1beta1.VolumePopulator, error) {
+	populator := &popv1beta1.VolumePopulator{}
+	if err := json.Unmarshal(raw.Raw, populator); err != nil {
+		return nil, err
+	}
+	return populator, nil
+}
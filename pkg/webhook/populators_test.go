@@ -0,0 +1,134 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	popv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type fakePopulatorValidator struct {
+	problems []string
+	warnings []string
+	err      error
+
+	oldPopulator *popv1beta1.VolumePopulator
+	newPopulator *popv1beta1.VolumePopulator
+}
+
+func (v *fakePopulatorValidator) ValidatePopulatorChange(oldPopulator, newPopulator *popv1beta1.VolumePopulator) ([]string, []string, error) {
+	v.oldPopulator = oldPopulator
+	v.newPopulator = newPopulator
+	return v.problems, v.warnings, v.err
+}
+
+func TestAdmitPopulators(t *testing.T) {
+	populatorKind := metav1.GroupVersionKind{Group: popv1beta1.GroupName, Version: "v1beta1", Kind: "VolumePopulator"}
+	raw, err := json.Marshal(&popv1beta1.VolumePopulator{ObjectMeta: metav1.ObjectMeta{Name: "valid"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := runtime.RawExtension{Raw: raw}
+
+	testCases := []struct {
+		name      string
+		validator *fakePopulatorValidator
+		request   *admissionv1.AdmissionRequest
+		allowed   bool
+		warnings  []string
+		message   string
+		old       bool
+		new       bool
+	}{
+		{
+			name:      "Create",
+			validator: &fakePopulatorValidator{warnings: []string{"not served"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: populatorKind, Object: object},
+			allowed:   true,
+			warnings:  []string{"not served"},
+			new:       true,
+		},
+		{
+			name:      "Create with problems",
+			validator: &fakePopulatorValidator{problems: []string{"already registered", "in use"}, warnings: []string{"not served"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: populatorKind, Object: object},
+			warnings:  []string{"not served"},
+			message:   "already registered; in use",
+			new:       true,
+		},
+		{
+			name:      "Update",
+			validator: &fakePopulatorValidator{},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Update, Kind: populatorKind, Object: object, OldObject: object},
+			allowed:   true,
+			old:       true,
+			new:       true,
+		},
+		{
+			name:      "Delete",
+			validator: &fakePopulatorValidator{problems: []string{"in use"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Delete, Kind: populatorKind, OldObject: object},
+			message:   "in use",
+			old:       true,
+		},
+		{
+			name:      "Validation error",
+			validator: &fakePopulatorValidator{problems: []string{"in use"}, err: errors.New("no cache")},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Delete, Kind: populatorKind, OldObject: object},
+			allowed:   true,
+			old:       true,
+		},
+		{
+			name:      "Invalid object",
+			validator: &fakePopulatorValidator{},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: populatorKind, Object: runtime.RawExtension{Raw: []byte("{")}},
+			message:   "failed to decode VolumePopulator: unexpected end of JSON input",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := AdmitPopulators(tc.validator)(tc.request)
+			if response.Allowed != tc.allowed {
+				t.Errorf(`expected "%v" to equal "%v"`, response.Allowed, tc.allowed)
+			}
+			if !reflect.DeepEqual(response.Warnings, tc.warnings) {
+				t.Errorf(`expected "%v" to equal "%v"`, response.Warnings, tc.warnings)
+			}
+			message := ""
+			if response.Result != nil {
+				message = response.Result.Message
+			}
+			if message != tc.message {
+				t.Errorf(`expected "%v" to equal "%v"`, message, tc.message)
+			}
+			if (tc.validator.oldPopulator != nil) != tc.old {
+				t.Errorf(`expected old populator "%v", got "%v"`, tc.old, tc.validator.oldPopulator)
+			}
+			if (tc.validator.newPopulator != nil) != tc.new {
+				t.Errorf(`expected new populator "%v", got "%v"`, tc.new, tc.validator.newPopulator)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// PVCValidator validates PVCs that are being created.
+type PVCValidator interface {
+	// ValidatePVCCreation returns the problems that reject the creation
+	// of a PVC by the given user, for example an exceeded population
+	// quota.
+	ValidatePVCCreation(pvc *v1.PersistentVolumeClaim, user authenticationv1.UserInfo) (problems []string, err error)
+}
+
+// AdmitPVCs returns an admission handler for PersistentVolumeClaims. It
+// rejects creations with problems and allows everything else.
+func AdmitPVCs(validator PVCValidator) AdmitFunc {
+	return func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		if request.Operation != admissionv1.Create ||
+			request.Kind != (metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "PersistentVolumeClaim"}) {
+			return allowed()
+		}
+
+		pvc := &v1.PersistentVolumeClaim{}
+		if err := json.Unmarshal(request.Object.Raw, pvc); err != nil {
+			return denied(metav1.StatusReasonBadRequest, "failed to decode PersistentVolumeClaim: %v", err)
+		}
+		if pvc.Namespace == "" {
+			pvc.Namespace = request.Namespace
+		}
+
+		problems, err := validator.ValidatePVCCreation(pvc, request.UserInfo)
+		if err != nil {
+			// The controller still reports problems with events, do
+			// not block PVCs while the caches are unavailable.
+			klog.Errorf("Failed to validate PersistentVolumeClaim %s/%s: %v", request.Namespace, request.Name, err)
+			return allowed()
+		}
+		if len(problems) > 0 {
+			return denied(metav1.StatusReasonForbidden, "%s", strings.Join(problems, "; "))
+		}
+		return allowed()
+	}
+}
+
+// PVCDefaulter sets defaults on PVCs that are being created.
+type PVCDefaulter interface {
+	// DefaultDataSource sets the default data source of a PVC without
+	// one, and returns warnings about defaults that were not set.
+	DefaultDataSource(pvc *v1.PersistentVolumeClaim) (warnings []string, err error)
+}
+
+// MutatePVCs returns a mutating admission handler for PersistentVolumeClaims.
+// It patches the dataSourceRef and the annotations set by the defaulter into
+// PVCs that are being created, and never rejects a PVC.
+func MutatePVCs(defaulter PVCDefaulter) AdmitFunc {
+	return func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		if request.Operation != admissionv1.Create ||
+			request.Kind != (metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "PersistentVolumeClaim"}) {
+			return allowed()
+		}
+
+		pvc := &v1.PersistentVolumeClaim{}
+		if err := json.Unmarshal(request.Object.Raw, pvc); err != nil {
+			return denied(metav1.StatusReasonBadRequest, "failed to decode PersistentVolumeClaim: %v", err)
+		}
+		if pvc.Namespace == "" {
+			pvc.Namespace = request.Namespace
+		}
+		original := pvc.DeepCopy()
+
+		warnings, err := defaulter.DefaultDataSource(pvc)
+		if err != nil {
+			// Defaults are best-effort, do not block PVCs while the
+			// caches are unavailable.
+			klog.Errorf("Failed to set defaults of PersistentVolumeClaim %s/%s: %v", request.Namespace, request.Name, err)
+			return allowed()
+		}
+
+		response, err := patched(pvcPatch(original, pvc), warnings...)
+		if err != nil {
+			klog.Errorf("Failed to patch PersistentVolumeClaim %s/%s: %v", request.Namespace, request.Name, err)
+			return allowed()
+		}
+		return response
+	}
+}
+
+// pvcPatch returns the JSON patch operations that add the dataSourceRef and
+// the annotations of the defaulted PVC to the original one.
+func pvcPatch(original, defaulted *v1.PersistentVolumeClaim) []patchOperation {
+	var patch []patchOperation
+	if original.Spec.DataSourceRef == nil && defaulted.Spec.DataSourceRef != nil {
+		patch = append(patch, patchOperation{Op: "add", Path: "/spec/dataSourceRef", Value: defaulted.Spec.DataSourceRef})
+	}
+	if original.Annotations == nil {
+		if len(defaulted.Annotations) > 0 {
+			patch = append(patch, patchOperation{Op: "add", Path: "/metadata/annotations", Value: defaulted.Annotations})
+		}
+		return patch
+	}
+	var keys []string
+	for key, value := range defaulted.Annotations {
+		if old, found := original.Annotations[key]; !found || old != value {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		patch = append(patch, patchOperation{Op: "add", Path: "/metadata/annotations/" + escapePointer(key), Value: defaulted.Annotations[key]})
+	}
+	return patch
+}
+
+// escapePointer escapes a key for use in a JSON pointer (RFC 6901).
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type fakePVCValidator struct {
+	problems []string
+	err      error
+
+	pvc  *v1.PersistentVolumeClaim
+	user authenticationv1.UserInfo
+}
+
+func (v *fakePVCValidator) ValidatePVCCreation(pvc *v1.PersistentVolumeClaim, user authenticationv1.UserInfo) ([]string, error) {
+	v.pvc = pvc
+	v.user = user
+	return v.problems, v.err
+}
+
+func TestAdmitPVCs(t *testing.T) {
+	pvcKind := metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "PersistentVolumeClaim"}
+	raw, err := json.Marshal(&v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "claim"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := runtime.RawExtension{Raw: raw}
+	user := authenticationv1.UserInfo{Username: "dev", Groups: []string{"developers"}}
+
+	testCases := []struct {
+		name      string
+		validator *fakePVCValidator
+		request   *admissionv1.AdmissionRequest
+		allowed   bool
+		message   string
+		validated bool
+	}{
+		{
+			name:      "Create",
+			validator: &fakePVCValidator{},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Namespace: "tenant", Object: object, UserInfo: user},
+			allowed:   true,
+			validated: true,
+		},
+		{
+			name:      "Create over quota",
+			validator: &fakePVCValidator{problems: []string{"quota exceeded"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Namespace: "tenant", Object: object},
+			message:   "quota exceeded",
+			validated: true,
+		},
+		{
+			name:      "Update",
+			validator: &fakePVCValidator{problems: []string{"quota exceeded"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Update, Kind: pvcKind, Namespace: "tenant", Object: object, OldObject: object},
+			allowed:   true,
+		},
+		{
+			name:      "Validation error",
+			validator: &fakePVCValidator{problems: []string{"quota exceeded"}, err: errors.New("no cache")},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Namespace: "tenant", Object: object},
+			allowed:   true,
+			validated: true,
+		},
+		{
+			name:      "Invalid object",
+			validator: &fakePVCValidator{},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Object: runtime.RawExtension{Raw: []byte("{")}},
+			message:   "failed to decode PersistentVolumeClaim: unexpected end of JSON input",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := AdmitPVCs(tc.validator)(tc.request)
+			if response.Allowed != tc.allowed {
+				t.Errorf(`expected "%v" to equal "%v"`, response.Allowed, tc.allowed)
+			}
+			message := ""
+			if response.Result != nil {
+				message = response.Result.Message
+			}
+			if message != tc.message {
+				t.Errorf(`expected "%v" to equal "%v"`, message, tc.message)
+			}
+			if (tc.validator.pvc != nil) != tc.validated {
+				t.Errorf(`expected validation "%v", got "%v"`, tc.validated, tc.validator.pvc)
+			}
+			// The namespace of the request is used for PVCs without one.
+			if tc.validator.pvc != nil && tc.validator.pvc.Namespace != "tenant" {
+				t.Errorf(`expected "%v" to equal "%v"`, tc.validator.pvc.Namespace, "tenant")
+			}
+			if tc.validator.pvc != nil && !reflect.DeepEqual(tc.validator.user, tc.request.UserInfo) {
+				t.Errorf(`expected "%v" to equal "%v"`, tc.validator.user, tc.request.UserInfo)
+			}
+		})
+	}
+}
+
+type fakePVCDefaulter struct {
+	dataSourceRef *v1.TypedObjectReference
+	annotations   map[string]string
+	warnings      []string
+	err           error
+}
+
+func (d *fakePVCDefaulter) DefaultDataSource(pvc *v1.PersistentVolumeClaim) ([]string, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.dataSourceRef != nil {
+		pvc.Spec.DataSourceRef = d.dataSourceRef
+	}
+	for key, value := range d.annotations {
+		if pvc.Annotations == nil {
+			pvc.Annotations = map[string]string{}
+		}
+		pvc.Annotations[key] = value
+	}
+	return d.warnings, nil
+}
+
+func TestMutatePVCs(t *testing.T) {
+	pvcKind := metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "PersistentVolumeClaim"}
+	rawPVC := func(annotations map[string]string) runtime.RawExtension {
+		raw, err := json.Marshal(&v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "claim", Annotations: annotations}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return runtime.RawExtension{Raw: raw}
+	}
+	dataSourceRef := &v1.TypedObjectReference{Kind: "PersistentVolumeClaim", Name: "golden"}
+	injected := map[string]string{"example.com/injected-source": "PersistentVolumeClaim/golden"}
+
+	testCases := []struct {
+		name      string
+		defaulter *fakePVCDefaulter
+		request   *admissionv1.AdmissionRequest
+		allowed   bool
+		message   string
+		patch     string
+		warnings  []string
+	}{
+		{
+			name:      "Inject without annotations",
+			defaulter: &fakePVCDefaulter{dataSourceRef: dataSourceRef, annotations: injected},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Namespace: "tenant", Object: rawPVC(nil)},
+			allowed:   true,
+			patch:     `[{"op":"add","path":"/spec/dataSourceRef","value":{"apiGroup":null,"kind":"PersistentVolumeClaim","name":"golden"}},{"op":"add","path":"/metadata/annotations","value":{"example.com/injected-source":"PersistentVolumeClaim/golden"}}]`,
+		},
+		{
+			name:      "Inject with annotations",
+			defaulter: &fakePVCDefaulter{dataSourceRef: dataSourceRef, annotations: injected},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Namespace: "tenant", Object: rawPVC(map[string]string{"owner": "dev"})},
+			allowed:   true,
+			patch:     `[{"op":"add","path":"/spec/dataSourceRef","value":{"apiGroup":null,"kind":"PersistentVolumeClaim","name":"golden"}},{"op":"add","path":"/metadata/annotations/example.com~1injected-source","value":"PersistentVolumeClaim/golden"}]`,
+		},
+		{
+			name:      "Not injected",
+			defaulter: &fakePVCDefaulter{warnings: []string{"not injected"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Namespace: "tenant", Object: rawPVC(nil)},
+			allowed:   true,
+			warnings:  []string{"not injected"},
+		},
+		{
+			name:      "Defaulting error",
+			defaulter: &fakePVCDefaulter{err: errors.New("no cache")},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Namespace: "tenant", Object: rawPVC(nil)},
+			allowed:   true,
+		},
+		{
+			name:      "Update",
+			defaulter: &fakePVCDefaulter{dataSourceRef: dataSourceRef},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Update, Kind: pvcKind, Namespace: "tenant", Object: rawPVC(nil), OldObject: rawPVC(nil)},
+			allowed:   true,
+		},
+		{
+			name:      "Invalid object",
+			defaulter: &fakePVCDefaulter{},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: pvcKind, Object: runtime.RawExtension{Raw: []byte("{")}},
+			message:   "failed to decode PersistentVolumeClaim: unexpected end of JSON input",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := MutatePVCs(tc.defaulter)(tc.request)
+			if response.Allowed != tc.allowed {
+				t.Errorf(`expected "%v" to equal "%v"`, response.Allowed, tc.allowed)
+			}
+			message := ""
+			if response.Result != nil {
+				message = response.Result.Message
+			}
+			if message != tc.message {
+				t.Errorf(`expected "%v" to equal "%v"`, message, tc.message)
+			}
+			if string(response.Patch) != tc.patch {
+				t.Errorf(`expected "%v" to equal "%v"`, string(response.Patch), tc.patch)
+			}
+			if (response.PatchType != nil) != (tc.patch != "") {
+				t.Errorf(`expected a patch type with patch "%v", got "%v"`, tc.patch, response.PatchType)
+			}
+			if !reflect.DeepEqual(response.Warnings, tc.warnings) {
+				t.Errorf(`expected "%v" to equal "%v"`, response.Warnings, tc.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook implements the optional admission webhooks of the
+// validator, which report data source problems when objects are applied
+// instead of after the fact.
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/klog/v2"
+)
+
+// maxRequestSize bounds admission reviews, like the API server bounds the
+// objects it stores.
+const maxRequestSize = 3 << 20
+
+// Timeouts of the webhook server. The API server waits at most 30 seconds
+// for a webhook, so slower clients are not the API server.
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 30 * time.Second
+)
+
+// AdmitFunc handles an admission request.
+type AdmitFunc func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+
+var (
+	scheme = runtime.NewScheme()
+	codecs = serializer.NewCodecFactory(scheme)
+)
+
+func init() {
+	admissionv1.AddToScheme(scheme)
+}
+
+// Server serves admission webhooks over TLS.
+type Server struct {
+	mux   *http.ServeMux
+	certs *certWatcher
+}
+
+// NewServer creates a server that uses the given certificate and key. The
+// files are read again when they change, so that certificates can be
+// rotated without a restart.
+func NewServer(certFile, keyFile string) (*Server, error) {
+	certs := &certWatcher{certFile: certFile, keyFile: keyFile}
+	if _, err := certs.getCertificate(nil); err != nil {
+		return nil, err
+	}
+	return &Server{
+		mux:   http.NewServeMux(),
+		certs: certs,
+	}, nil
+}
+
+// Handle registers the admission handler for the given path.
+func (s *Server) Handle(path string, admit AdmitFunc) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, admit)
+	})
+}
+
+// Run serves the webhooks on addr until stopCh is closed.
+func (s *Server) Run(addr string, stopCh <-chan struct{}) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: s.certs.getCertificate,
+		},
+	}
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			klog.Errorf("Failed to shutdown webhook server: %v", err)
+		}
+	}()
+	klog.Infof("Starting webhook server on %s", addr)
+	if err := srv.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// serve decodes an AdmissionReview, passes its request to admit and writes
+// the response.
+func serve(w http.ResponseWriter, r *http.Request, admit AdmitFunc) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+		klog.Errorf("contentType=%s, expected application/json", contentType)
+		http.Error(w, "expected Content-Type application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		klog.Errorf("failed to read admission request: %v", err)
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	review := &admissionv1.AdmissionReview{}
+	if _, _, err := codecs.UniversalDeserializer().Decode(body, nil, review); err != nil {
+		klog.Errorf("failed to decode admission review: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review without request", http.StatusBadRequest)
+		return
+	}
+
+	response := admit(review.Request)
+	response.UID = review.Request.UID
+	review.Response = response
+	review.Request = nil
+
+	respBytes, err := json.Marshal(review)
+	if err != nil {
+		klog.Errorf("failed to encode admission review: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(respBytes); err != nil {
+		klog.Errorf("failed to write admission response: %v", err)
+	}
+}
+
+// allowed returns a response that admits the object with the given
+// warnings.
+func allowed(warnings ...string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed:  true,
+		Warnings: warnings,
+	}
+}
+
+// patched returns a response that admits the object, changed by the given
+// JSON patch operations, with the given warnings.
+func patched(patch []patchOperation, warnings ...string) (*admissionv1.AdmissionResponse, error) {
+	if len(patch) == 0 {
+		return allowed(warnings...), nil
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patchBytes,
+		PatchType: &patchType,
+		Warnings:  warnings,
+	}, nil
+}
+
+// patchOperation is an operation of a JSON patch (RFC 6902).
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// denied returns a response that rejects the object.
+func denied(reason metav1.StatusReason, format string, args ...interface{}) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  reason,
+			Message: fmt.Sprintf(format, args...),
+		},
+	}
+}
+
+// certWatcher loads a certificate and key pair and loads it again when
+// either file is modified.
+type certWatcher struct {
+	certFile string
+	keyFile  string
+
+	lock     sync.Mutex
+	cert     *tls.Certificate
+	certTime time.Time
+	keyTime  time.Time
+}
+
+func (c *certWatcher) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certInfo, err := os.Stat(c.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo, err := os.Stat(c.keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.cert != nil && certInfo.ModTime().Equal(c.certTime) && keyInfo.ModTime().Equal(c.keyTime) {
+		return c.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		if c.cert != nil {
+			// The files may be in the middle of an update, keep
+			// using the old pair.
+			klog.Warningf("Failed to reload webhook certificate: %v", err)
+			return c.cert, nil
+		}
+		return nil, fmt.Errorf("failed to load webhook certificate: %w", err)
+	}
+	klog.V(2).Infof("Loaded webhook certificate from %s", c.certFile)
+	c.cert = &cert
+	c.certTime = certInfo.ModTime()
+	c.keyTime = keyInfo.ModTime()
+	return c.cert, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// WorkloadValidator validates the PVC templates of workloads.
+type WorkloadValidator interface {
+	// ValidateWorkload returns a warning for each PVC template of a
+	// StatefulSet or a Pod whose data source would be rejected.
+	ValidateWorkload(obj runtime.Object) ([]string, error)
+}
+
+// AdmitWorkloads returns an admission handler for StatefulSets and Pods. It
+// never rejects a workload, but returns the problems with its PVC templates
+// as warnings, which kubectl shows when the manifest is applied.
+func AdmitWorkloads(validator WorkloadValidator) AdmitFunc {
+	return func(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+			return allowed()
+		}
+
+		var obj runtime.Object
+		switch request.Kind {
+		case metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}:
+			obj = &appsv1.StatefulSet{}
+		case metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}:
+			obj = &v1.Pod{}
+		default:
+			return allowed()
+		}
+		if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+			return denied(metav1.StatusReasonBadRequest, "failed to decode %s: %v", request.Kind.Kind, err)
+		}
+
+		warnings, err := validator.ValidateWorkload(obj)
+		if err != nil {
+			// Validation is advisory, do not block the workload.
+			klog.Errorf("Failed to validate %s %s/%s: %v", request.Kind.Kind, request.Namespace, request.Name, err)
+			return allowed()
+		}
+		return allowed(warnings...)
+	}
+}
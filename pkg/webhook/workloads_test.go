@@ -0,0 +1,149 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type fakeWorkloadValidator struct {
+	warnings []string
+	err      error
+}
+
+func (v *fakeWorkloadValidator) ValidateWorkload(obj runtime.Object) ([]string, error) {
+	if _, ok := obj.(*appsv1.StatefulSet); !ok {
+		return nil, errors.New("unexpected object")
+	}
+	return v.warnings, v.err
+}
+
+func TestAdmitWorkloads(t *testing.T) {
+	statefulSetKind := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}
+	raw, err := json.Marshal(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name      string
+		validator *fakeWorkloadValidator
+		request   *admissionv1.AdmissionRequest
+		allowed   bool
+		warnings  []string
+	}{
+		{
+			name:      "Warnings",
+			validator: &fakeWorkloadValidator{warnings: []string{"bad template"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: statefulSetKind, Object: runtime.RawExtension{Raw: raw}},
+			allowed:   true,
+			warnings:  []string{"bad template"},
+		},
+		{
+			name:      "Validation error",
+			validator: &fakeWorkloadValidator{err: errors.New("no cache")},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Update, Kind: statefulSetKind, Object: runtime.RawExtension{Raw: raw}},
+			allowed:   true,
+		},
+		{
+			name:      "Delete",
+			validator: &fakeWorkloadValidator{warnings: []string{"bad template"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Delete, Kind: statefulSetKind},
+			allowed:   true,
+		},
+		{
+			name:      "Other kind",
+			validator: &fakeWorkloadValidator{warnings: []string{"bad template"}},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}},
+			allowed:   true,
+		},
+		{
+			name:      "Invalid object",
+			validator: &fakeWorkloadValidator{},
+			request:   &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Kind: statefulSetKind, Object: runtime.RawExtension{Raw: []byte("{")}},
+			allowed:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := AdmitWorkloads(tc.validator)(tc.request)
+			if response.Allowed != tc.allowed {
+				t.Errorf(`expected "%v" to equal "%v"`, response.Allowed, tc.allowed)
+			}
+			if !reflect.DeepEqual(response.Warnings, tc.warnings) {
+				t.Errorf(`expected "%v" to equal "%v"`, response.Warnings, tc.warnings)
+			}
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  &admissionv1.AdmissionRequest{UID: "1234", Operation: admissionv1.Delete},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	serve(recorder, request, func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		return allowed("checked")
+	})
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf(`expected "%v" to equal "%v"`, recorder.Code, http.StatusOK)
+	}
+	var result admissionv1.AdmissionReview
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Response == nil || result.Response.UID != "1234" || !result.Response.Allowed {
+		t.Errorf("unexpected response %+v", result.Response)
+	}
+}
+
+func TestServeTooLarge(t *testing.T) {
+	body := bytes.Repeat([]byte(" "), maxRequestSize+1)
+	request := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	serve(recorder, request, func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		t.Errorf("unexpected admission request")
+		return allowed()
+	})
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf(`expected "%v" to equal "%v"`, recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+}